@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	"workspace-channels-cleaner/config"
+	"workspace-channels-cleaner/slack"
+)
+
+// runAnalyze runs the stale-channel scan over a workspace export instead of the live API
+func runAnalyze(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("analyze", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "table", "output format: json, ndjson or table")
+	configPath := fs.String("config", config.GetConfigPath(), "path to the configuration file")
+	asOf := fs.String("as-of", "", "judge staleness as of this date (YYYY-MM-DD) instead of today")
+	user := fs.String("user", "", "user ID whose own activity counts with \"staleness\": \"self\"")
+	top := fs.Int("top", 3, "number of top posters to report per channel")
+	policyExpr := fs.String("policy", "", "report the channels this expression holds for as stale, instead of the configured policy")
+	if err := fs.Parse(args); err != nil {
+		return ExitError
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(stderr, "Usage: workspace-cleaner-tui analyze [flags] export.zip")
+		return ExitError
+	}
+	if *top < 0 {
+		fmt.Fprintf(stderr, "❌ invalid --top %d (must be 0 or more)\n", *top)
+		return ExitError
+	}
+	if !isValidFormat(*format) {
+		fmt.Fprintf(stderr, "❌ unknown format %q (must be json, ndjson or table)\n", *format)
+		return ExitError
+	}
+
+	appConfig, err := config.LoadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "❌ %v\n", err)
+		return ExitError
+	}
+	if *policyExpr != "" {
+		appConfig.Policy = *policyExpr
+	}
+	if err := config.ValidateConfig(appConfig); err != nil {
+		fmt.Fprintf(stderr, "❌ invalid configuration: %v\n", err)
+		return ExitError
+	}
+	if appConfig.Staleness == config.StalenessSelf && *user == "" {
+		fmt.Fprintln(stderr, "❌ an export has no signed-in user; pass --user with \"staleness\": \"self\"")
+		return ExitError
+	}
+
+	archive, err := slack.OpenExport(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "❌ %v\n", err)
+		return ExitError
+	}
+	cleaner := slack.NewOfflineCleaner(archive, appConfig)
+	cleaner.Out = stderr // Keep stdout machine-readable
+	cleaner.UserID = *user
+	if *asOf != "" {
+		at, err := time.ParseInLocation("2006-01-02", *asOf, time.Local)
+		if err != nil {
+			fmt.Fprintf(stderr, "❌ invalid --as-of date %q (expected YYYY-MM-DD)\n", *asOf)
+			return ExitError
+		}
+		cleaner.Cutoff = at.AddDate(0, 0, -appConfig.Days)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	channels, err := cleaner.GetFilteredChannels(ctx)
+	if err != nil {
+		fmt.Fprintf(stderr, "❌ analysis failed: %v\n", err)
+		return ExitError
+	}
+	archive.AddTopPosters(channels, *top)
+
+	if err := writeChannels(stdout, *format, channels, cleaner.SelfActivity); err != nil {
+		fmt.Fprintf(stderr, "❌ failed to write output: %v\n", err)
+		return ExitError
+	}
+
+	// Same exit codes as scan: an incomplete analysis must not look like a clean one
+	undetermined := 0
+	for _, ch := range channels {
+		if ch.Undetermined() {
+			fmt.Fprintf(stderr, "⚠️  #%s: could not determine activity: %s\n", ch.Name, ch.Error)
+			undetermined++
+		}
+	}
+	if undetermined > 0 {
+		return ExitError
+	}
+	if len(channels) == 0 {
+		return ExitOK
+	}
+	return ExitStale
+}
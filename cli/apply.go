@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"workspace-channels-cleaner/config"
+	"workspace-channels-cleaner/plan"
+	"workspace-channels-cleaner/slack"
+)
+
+// runApply leaves, mutes, archives or posts notices to the channels in a plan file that are still stale
+func runApply(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("apply", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	yes := fs.Bool("yes", false, "skip the interactive confirmation")
+	if err := fs.Parse(args); err != nil {
+		return ExitError
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(stderr, "Usage: workspace-cleaner-tui apply [--yes] plan.json")
+		return ExitError
+	}
+
+	p, err := plan.Load(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "❌ %v\n", err)
+		return ExitError
+	}
+	// The plan may have been edited during review, so check it like a config file
+	if err := config.ValidateConfig(&p.Config); err != nil {
+		fmt.Fprintf(stderr, "❌ invalid plan configuration: %v\n", err)
+		return ExitError
+	}
+
+	if err := checkArchiveStaleness(p.Action, &p.Config); err != nil {
+		fmt.Fprintf(stderr, "❌ %v\n", err)
+		return ExitError
+	}
+
+	if !requireToken(stderr) {
+		return ExitError
+	}
+	cleaner := slack.NewCleanerFromConfig(config.GetWorkspaceToken(), &p.Config)
+	cleaner.Cutoff = p.Cutoff
+	if err := cleaner.SkipListErr(); err != nil {
+		fmt.Fprintf(stderr, "❌ %v\n", err)
+		return ExitError
+	}
+
+	fmt.Fprintf(stdout, "Plan from %s: %s %d channel(s), cutoff %s\n", p.CreatedAt.Local().Format("2006-01-02 15:04"), p.Action, len(p.Channels), p.Cutoff.Local().Format("2006-01-02"))
+
+	stale, unchecked := recheckPlan(cleaner, p, stdout)
+	if len(stale) == 0 {
+		fmt.Fprintln(stdout, "Nothing to apply: no planned channel is still stale.")
+		if unchecked > 0 {
+			return ExitError
+		}
+		return ExitOK
+	}
+
+	fmt.Fprintf(stdout, "\n%d channel(s) will be %s:\n", len(stale), p.Action.Done())
+	for _, ch := range stale {
+		if p.Action == slack.ActionLeave && ch.Rule != nil && ch.Rule.Action == slack.ActionMute {
+			fmt.Fprintf(stdout, "  - #%s (%s), muted instead by rule %s\n", ch.Name, ch.ID, ch.Rule.Name)
+			continue
+		}
+		fmt.Fprintf(stdout, "  - #%s (%s)\n", ch.Name, ch.ID)
+	}
+
+	// Archiving hits every member of the channel, so it takes a deliberate answer
+	answer, what := "yes", "leave these channels"
+	switch p.Action {
+	case slack.ActionArchive:
+		answer, what = "archive", "archive these channels"
+	case slack.ActionMute:
+		what = "mute these channels"
+	case slack.ActionNotice:
+		what = fmt.Sprintf("post the pre-archive notice to these channels (archived after %d days)", p.Config.Notice.GraceDays)
+	}
+	if !*yes && !confirm(stdin, stdout, fmt.Sprintf("\nType '%s' to %s: ", answer, what), answer) {
+		fmt.Fprintln(stdout, "Apply cancelled.")
+		return ExitError
+	}
+
+	report := cleaner.Apply(p.Action, stale)
+	writeLeaveReport(stdout, report)
+	if len(report.Failed) > 0 || report.JournalErr != nil || unchecked > 0 {
+		return ExitError
+	}
+	return ExitOK
+}
+
+// recheckPlan returns the planned channels that are still stale and not protected,
+// reporting every channel that was dropped and why. Channels that can't be
+// re-checked, e.g. because they were deleted, are dropped too and counted.
+func recheckPlan(cleaner *slack.Cleaner, p *plan.Plan, out io.Writer) (stale []slack.ChannelInfo, unchecked int) {
+	for _, ch := range p.Channels {
+		if pattern, ok := cleaner.SkipList.Match(ch.ID, ch.Name); ok {
+			fmt.Fprintf(out, "  ~ #%s: now protected by skip list entry %q\n", ch.Name, pattern)
+			continue
+		}
+
+		ch, ok, err := cleaner.RecheckChannel(context.Background(), ch)
+		if err != nil {
+			fmt.Fprintf(out, "  ~ #%s: could not re-check (%v)\n", ch.Name, err)
+			unchecked++
+			continue
+		}
+		if !ok {
+			lastSeen := ch.LastSeen
+			if cleaner.SelfActivity {
+				lastSeen = ch.MyLastSeen
+			}
+			fmt.Fprintf(out, "  ~ #%s: active since plan (last activity %s)\n", ch.Name, lastSeen.Local().Format("2006-01-02 15:04"))
+			continue
+		}
+		stale = append(stale, ch)
+	}
+	return stale, unchecked
+}
+
+// confirm asks the user to type answer
+func confirm(stdin io.Reader, out io.Writer, prompt, answer string) bool {
+	fmt.Fprint(out, prompt)
+	line, _ := bufio.NewReader(stdin).ReadString('\n')
+	return strings.TrimSpace(line) == answer
+}
@@ -0,0 +1,185 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"workspace-channels-cleaner/config"
+	"workspace-channels-cleaner/plan"
+	"workspace-channels-cleaner/slack"
+	"workspace-channels-cleaner/slack/fakeapi"
+)
+
+// newTestWorkspace starts a fake API for the subcommands and moves into an empty
+// directory, where the journal, skip list and cache are kept under config/
+func newTestWorkspace(t *testing.T) *fakeapi.Server {
+	t.Helper()
+	srv := fakeapi.New()
+	t.Cleanup(srv.Close)
+	t.Setenv("WORKSPACE_API_TOKEN", fakeapi.Token)
+	t.Setenv("WORKSPACE_API_URL", srv.URL())
+	t.Chdir(t.TempDir())
+	if err := os.Mkdir("config", 0755); err != nil {
+		t.Fatal(err)
+	}
+	return srv
+}
+
+// writePlan saves a leave plan for the given channels at the default settings
+func writePlan(t *testing.T, channels ...slack.ChannelInfo) string {
+	t.Helper()
+	appConfig := config.DefaultConfig()
+	p := plan.New(appConfig, time.Now().AddDate(0, 0, -appConfig.Days), slack.ActionLeave, channels)
+	path := filepath.Join(t.TempDir(), "plan.json")
+	if err := plan.Save(path, p); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	return path
+}
+
+func run(cmd func([]string, io.Reader, io.Writer, io.Writer) int, stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := cmd(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestScanExitCodes(t *testing.T) {
+	srv := newTestWorkspace(t)
+	srv.AddChannel(fakeapi.Channel("C1", "busy", false), fakeapi.Message("U1", time.Now()))
+
+	if code, _, stderr := run(runScan, "", "--format", "json"); code != ExitOK {
+		t.Errorf("scan without stale channels exited %d, want %d: %s", code, ExitOK, stderr)
+	}
+
+	srv.AddChannel(fakeapi.Channel("C2", "quiet", false), fakeapi.Message("U1", time.Now().AddDate(0, 0, -90)))
+	code, stdout, stderr := run(runScan, "", "--format", "json", "--rescan")
+	if code != ExitStale {
+		t.Errorf("scan with a stale channel exited %d, want %d: %s", code, ExitStale, stderr)
+	}
+	if !strings.Contains(stdout, `"id": "C2"`) || strings.Contains(stdout, `"id": "C1"`) {
+		t.Errorf("scan printed %s, want only C2", stdout)
+	}
+	if strings.Contains(stdout, "my_last_seen") {
+		t.Errorf("scan printed my_last_seen without self activity:\n%s", stdout)
+	}
+
+	if code, _, _ := run(runScan, "", "--format", "xml"); code != ExitError {
+		t.Errorf("scan with an unknown format exited %d, want %d", code, ExitError)
+	}
+}
+
+func TestApplyDropsChannelsActiveSincePlan(t *testing.T) {
+	srv := newTestWorkspace(t)
+	old := time.Now().AddDate(0, 0, -90)
+	srv.AddChannel(fakeapi.Channel("C1", "still-quiet", false), fakeapi.Message("U1", old))
+	srv.AddChannel(fakeapi.Channel("C2", "woke-up", false), fakeapi.Message("U1", old))
+	srv.AddChannel(fakeapi.Channel("C3", "now-protected", false), fakeapi.Message("U1", old))
+	path := writePlan(t,
+		slack.ChannelInfo{ID: "C1", Name: "still-quiet", Type: "public", LastSeen: old},
+		slack.ChannelInfo{ID: "C2", Name: "woke-up", Type: "public", LastSeen: old},
+		slack.ChannelInfo{ID: "C3", Name: "now-protected", Type: "public", LastSeen: old})
+	srv.Post("C2", fakeapi.Message("U2", time.Now()))
+	if err := os.WriteFile(slack.DefaultSkipListPath, []byte(`["now-*"]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	code, stdout, stderr := run(runApply, "", "--yes", path)
+	if code != ExitOK {
+		t.Fatalf("apply exited %d, want %d: %s", code, ExitOK, stderr)
+	}
+	if srv.IsMember("C1") {
+		t.Error("still-quiet was not left")
+	}
+	if !srv.IsMember("C2") {
+		t.Error("woke-up was left although it is active again")
+	}
+	if !srv.IsMember("C3") {
+		t.Error("now-protected was left although it was added to the skip list")
+	}
+	if !strings.Contains(stdout, "#woke-up: active since plan") {
+		t.Errorf("apply didn't report the dropped channel:\n%s", stdout)
+	}
+	if !strings.Contains(stdout, `#now-protected: now protected by skip list entry "now-*"`) {
+		t.Errorf("apply didn't report the newly protected channel:\n%s", stdout)
+	}
+
+	path = writePlan(t, slack.ChannelInfo{ID: "C2", Name: "woke-up", Type: "public", LastSeen: old})
+	code, stdout, _ = run(runApply, "", "--yes", path)
+	if code != ExitOK || !strings.Contains(stdout, "Nothing to apply") {
+		t.Errorf("apply of only active channels exited %d with %q, want %d and nothing to apply", code, stdout, ExitOK)
+	}
+}
+
+func TestApplyDropsChannelsThatCantBeRechecked(t *testing.T) {
+	srv := newTestWorkspace(t)
+	old := time.Now().AddDate(0, 0, -90)
+	srv.AddChannel(fakeapi.Channel("C1", "still-quiet", false), fakeapi.Message("U1", old))
+	srv.AddChannel(fakeapi.Channel("C2", "deleted", false), fakeapi.Message("U1", old))
+	srv.BreakHistory("C2", "channel_not_found")
+	path := writePlan(t,
+		slack.ChannelInfo{ID: "C1", Name: "still-quiet", Type: "public", LastSeen: old},
+		slack.ChannelInfo{ID: "C2", Name: "deleted", Type: "public", LastSeen: old})
+
+	code, stdout, _ := run(runApply, "", "--yes", path)
+	if code != ExitError {
+		t.Errorf("apply with a channel that couldn't be re-checked exited %d, want %d", code, ExitError)
+	}
+	if srv.IsMember("C1") {
+		t.Error("still-quiet was not left")
+	}
+	if !srv.IsMember("C2") {
+		t.Error("the channel that couldn't be re-checked was left")
+	}
+	if !strings.Contains(stdout, "#deleted: could not re-check (channel_not_found)") {
+		t.Errorf("apply didn't report the unchecked channel:\n%s", stdout)
+	}
+}
+
+func TestApplyExitCodes(t *testing.T) {
+	srv := newTestWorkspace(t)
+	old := time.Now().AddDate(0, 0, -90)
+	srv.AddChannel(fakeapi.Channel("C1", "quiet", false), fakeapi.Message("U1", old))
+	path := writePlan(t, slack.ChannelInfo{ID: "C1", Name: "quiet", Type: "public", LastSeen: old})
+
+	if code, _, _ := run(runApply, ""); code != ExitError {
+		t.Errorf("apply without a plan exited %d, want %d", code, ExitError)
+	}
+	if code, _, _ := run(runApply, "", filepath.Join(t.TempDir(), "missing.json")); code != ExitError {
+		t.Errorf("apply with a missing plan exited %d, want %d", code, ExitError)
+	}
+
+	edited, err := plan.Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	edited.Config.Staleness = "everyone"
+	editedPath := filepath.Join(t.TempDir(), "edited.json")
+	if err := plan.Save(editedPath, edited); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if code, _, stderr := run(runApply, "", "--yes", editedPath); code != ExitError || !strings.Contains(stderr, "invalid staleness mode") {
+		t.Errorf("apply of a plan with an invalid config exited %d with %q, want %d", code, stderr, ExitError)
+	}
+	if n := srv.Calls("conversations.history"); n != 0 || !srv.IsMember("C1") {
+		t.Errorf("apply of an invalid plan made %d history calls, want none", n)
+	}
+
+	code, stdout, _ := run(runApply, "no\n", path)
+	if code != ExitError || !strings.Contains(stdout, "Apply cancelled.") || !srv.IsMember("C1") {
+		t.Errorf("declined apply exited %d with member %t, want %d and no leave", code, srv.IsMember("C1"), ExitError)
+	}
+
+	srv.Fail("conversations.leave", 1, "internal_error")
+	if code, _, _ := run(runApply, "yes\n", path); code != ExitError || !srv.IsMember("C1") {
+		t.Errorf("apply with a failed leave exited %d, want %d", code, ExitError)
+	}
+
+	if code, _, stderr := run(runApply, "yes\n", path); code != ExitOK || srv.IsMember("C1") {
+		t.Errorf("confirmed apply exited %d, want %d and C1 left: %s", code, ExitOK, stderr)
+	}
+}
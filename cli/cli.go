@@ -0,0 +1,66 @@
+// Package cli implements the non-interactive subcommands of the cleaner.
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"workspace-channels-cleaner/config"
+)
+
+// Exit codes shared by all subcommands
+const (
+	ExitOK    = 0 // Command succeeded; for scan, nothing stale was found
+	ExitError = 1 // Command failed
+	ExitStale = 2 // Scan found stale channels
+)
+
+// IsCommand reports whether name is a known subcommand
+func IsCommand(name string) bool {
+	_, ok := commands[name]
+	return ok
+}
+
+// commands maps subcommand names to their entry points
+var commands = map[string]func(args []string, stdin io.Reader, stdout, stderr io.Writer) int{
+	"scan":    runScan,
+	"apply":   runApply,
+	"rejoin":  runRejoin,
+	"notices": runNotices,
+	"analyze": runAnalyze,
+}
+
+// Run executes the subcommand named by args[0] and returns the process exit code
+func Run(args []string) int {
+	if len(args) == 0 || !IsCommand(args[0]) {
+		printUsage(os.Stderr)
+		return ExitError
+	}
+	return commands[args[0]](args[1:], os.Stdin, os.Stdout, os.Stderr)
+}
+
+// requireToken reports whether a workspace token is set, explaining on stderr when
+// it isn't; only the commands that call the API need one
+func requireToken(stderr io.Writer) bool {
+	if err := config.ValidateToken(); err != nil {
+		fmt.Fprintf(stderr, "❌ %v\n", err)
+		return false
+	}
+	return true
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: workspace-cleaner-tui [command] [flags]")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Without a command the interactive TUI is started.")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Commands:")
+	fmt.Fprintln(w, "  scan    Find stale channels and print them (json, ndjson or table)")
+	fmt.Fprintln(w, "          --out plan.json also writes a plan for review (--action leave, mute, archive or notice)")
+	fmt.Fprintln(w, "          --muted lists only muted channels that are still stale")
+	fmt.Fprintln(w, "  apply   Leave, archive or notify the channels in a plan file that are still stale")
+	fmt.Fprintln(w, "  rejoin  List channels left by the cleaner, or rejoin them (--all or by ID/name)")
+	fmt.Fprintln(w, "  notices List pre-archive notices, or archive the uncontested ones (--archive)")
+	fmt.Fprintln(w, "  analyze Scan a workspace export ZIP offline, with member counts and top posters")
+}
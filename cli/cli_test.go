@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	goslack "github.com/slack-go/slack"
+
+	"workspace-channels-cleaner/slack"
+	"workspace-channels-cleaner/slack/fakeapi"
+)
+
+// writeExport writes a workspace export with one channel whose last message is 90 days old
+func writeExport(t *testing.T) string {
+	t.Helper()
+	var ch goslack.Channel
+	ch.ID, ch.Name, ch.Members = "C1", "old-project", []string{"U1", "U2"}
+	files := map[string]interface{}{
+		"channels.json":               []goslack.Channel{ch},
+		"old-project/2024-01-01.json": []goslack.Message{fakeapi.Message("U1", time.Now().AddDate(0, 0, -90))},
+	}
+
+	name := filepath.Join(t.TempDir(), "export.zip")
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for path, content := range files {
+		w, err := zw.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.NewEncoder(w).Encode(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+func TestOnlyCommandsCallingTheAPINeedAToken(t *testing.T) {
+	newTestWorkspace(t)
+	t.Setenv("WORKSPACE_API_TOKEN", "")
+	journal := filepath.Join(t.TempDir(), "journal.json")
+	if err := slack.SaveJournal(journal, []slack.JournalEntry{{ChannelID: "C1", Name: "alpha", Type: "public", LeftAt: time.Now()}}); err != nil {
+		t.Fatal(err)
+	}
+	notices := filepath.Join(t.TempDir(), "notices.json")
+	if err := slack.SaveNotices(notices, []slack.Notice{{ChannelID: "C1", Name: "alpha", PostedAt: time.Now(), ArchiveAfter: time.Now()}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if code, _, stderr := run(runAnalyze, "", writeExport(t)); code != ExitStale {
+		t.Errorf("analyze without a token exited %d, want %d: %s", code, ExitStale, stderr)
+	}
+	if code, stdout, _ := run(runRejoin, "", "--journal", journal); code != ExitOK || !strings.Contains(stdout, "#alpha") {
+		t.Errorf("listing the journal without a token exited %d:\n%s", code, stdout)
+	}
+	if code, stdout, _ := run(runNotices, "", "--notices", notices); code != ExitOK || !strings.Contains(stdout, "#alpha") {
+		t.Errorf("listing notices without a token exited %d:\n%s", code, stdout)
+	}
+
+	for _, tc := range []struct {
+		name string
+		cmd  func() (int, string, string)
+	}{
+		{"scan", func() (int, string, string) { return run(runScan, "") }},
+		{"apply", func() (int, string, string) { return run(runApply, "", "--yes", writePlan(t)) }},
+		{"rejoin", func() (int, string, string) { return run(runRejoin, "", "--journal", journal, "--all") }},
+		{"notices --archive", func() (int, string, string) { return run(runNotices, "", "--notices", notices, "--archive", "--yes") }},
+	} {
+		if code, _, stderr := tc.cmd(); code != ExitError || !strings.Contains(stderr, "WORKSPACE_API_TOKEN not set") {
+			t.Errorf("%s without a token exited %d with %q, want %d and a missing token error", tc.name, code, stderr, ExitError)
+		}
+	}
+}
+
+func TestAnalyzeRejectsNegativeTop(t *testing.T) {
+	newTestWorkspace(t)
+	export := writeExport(t)
+
+	if code, _, stderr := run(runAnalyze, "", "--top", "-1", export); code != ExitError || !strings.Contains(stderr, "invalid --top") {
+		t.Errorf("analyze --top -1 exited %d with %q, want %d and a usage error", code, stderr, ExitError)
+	}
+	code, stdout, stderr := run(runAnalyze, "", "--top", "0", "--format", "json", export)
+	if code != ExitStale || strings.Contains(stdout, `"user"`) {
+		t.Errorf("analyze --top 0 exited %d with %s, want %d and no top posters: %s", code, stdout, ExitStale, stderr)
+	}
+}
@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"workspace-channels-cleaner/config"
+	"workspace-channels-cleaner/slack"
+)
+
+// runNotices lists posted pre-archive notices, or archives the channels whose grace
+// period ended without anyone objecting
+func runNotices(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("notices", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	archive := fs.Bool("archive", false, "archive channels whose grace period is over and that saw no new activity")
+	yes := fs.Bool("yes", false, "skip the interactive confirmation")
+	configPath := fs.String("config", config.GetConfigPath(), "path to the configuration file")
+	noticesPath := fs.String("notices", slack.DefaultNoticesPath, "path to the notices file")
+	if err := fs.Parse(args); err != nil {
+		return ExitError
+	}
+
+	notices, err := slack.LoadNotices(*noticesPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "❌ %v\n", err)
+		return ExitError
+	}
+	if !*archive {
+		return listNotices(stdout, notices)
+	}
+	if len(notices) == 0 {
+		fmt.Fprintln(stdout, "No pending notices.")
+		return ExitOK
+	}
+
+	appConfig, err := config.LoadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "❌ %v\n", err)
+		return ExitError
+	}
+	if !requireToken(stderr) {
+		return ExitError
+	}
+	cleaner := slack.NewCleanerFromConfig(config.GetWorkspaceToken(), appConfig)
+	cleaner.Out = stderr
+	cleaner.NoticesPath = *noticesPath
+
+	reviews := cleaner.ReviewNotices(context.Background(), notices)
+	ready := 0
+	for _, r := range reviews {
+		switch {
+		case r.Err != nil:
+			fmt.Fprintf(stdout, "  ❌ #%s: could not re-check: %v\n", r.Notice.Name, r.Err)
+		case r.Status == slack.NoticeWaiting:
+			fmt.Fprintf(stdout, "  ⏳ #%s: grace period ends %s\n", r.Notice.Name, r.Notice.ArchiveAfter.Local().Format("2006-01-02 15:04"))
+		case r.Status == slack.NoticeObjected:
+			fmt.Fprintf(stdout, "  ~ #%s: active since the notice, will be dropped\n", r.Notice.Name)
+		default:
+			fmt.Fprintf(stdout, "  🗄️  #%s: no activity since the notice\n", r.Notice.Name)
+			ready++
+		}
+	}
+
+	if ready > 0 && !*yes && !confirm(stdin, stdout, fmt.Sprintf("\nType 'archive' to archive %d channel(s): ", ready), "archive") {
+		fmt.Fprintln(stdout, "Archive cancelled.")
+		return ExitError
+	}
+
+	report := cleaner.ResolveNotices(reviews)
+	writeLeaveReport(stdout, report)
+	if len(report.Failed) > 0 || report.JournalErr != nil {
+		return ExitError
+	}
+	return ExitOK
+}
+
+func listNotices(w io.Writer, notices []slack.Notice) int {
+	if len(notices) == 0 {
+		fmt.Fprintln(w, "No pending notices.")
+		return ExitOK
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tCHANNEL\tPOSTED AT\tARCHIVE AFTER")
+	for _, n := range notices {
+		after := n.ArchiveAfter.Local().Format("2006-01-02 15:04")
+		if time.Now().After(n.ArchiveAfter) {
+			after += " (due)"
+		}
+		fmt.Fprintf(tw, "%s\t#%s\t%s\t%s\n", n.ChannelID, n.Name, n.PostedAt.Local().Format("2006-01-02 15:04"), after)
+	}
+	tw.Flush()
+	fmt.Fprintln(w, "\nRun 'notices --archive' to archive the due channels nobody objected for.")
+	return ExitOK
+}
@@ -0,0 +1,194 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"workspace-channels-cleaner/slack"
+)
+
+func isValidFormat(format string) bool {
+	switch format {
+	case "json", "ndjson", "table":
+		return true
+	}
+	return false
+}
+
+// writeChannels renders the scan results in the requested format. With self set,
+// the table shows the user's own last activity next to the channel's; top posters
+// get a column when known, as for a workspace export, and so do matched rules.
+func writeChannels(w io.Writer, format string, channels []slack.ChannelInfo, self bool) error {
+	switch format {
+	case "json":
+		if channels == nil {
+			channels = []slack.ChannelInfo{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(channels)
+	case "ndjson":
+		enc := json.NewEncoder(w)
+		for _, ch := range channels {
+			if err := enc.Encode(ch); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "table":
+		posters, rules := false, false
+		for _, ch := range channels {
+			posters = posters || len(ch.TopPosters) > 0
+			rules = rules || ch.Rule != nil
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprint(tw, "ID\tCHANNEL\tTYPE\tMEMBERS\tLAST ACTIVITY\tSOURCE")
+		if self {
+			fmt.Fprint(tw, "\tMY LAST ACTIVITY")
+		}
+		fmt.Fprint(tw, "\tCREATED\tCREATOR\tFLAGS\tTOPIC\tPURPOSE")
+		if posters {
+			fmt.Fprint(tw, "\tTOP POSTERS")
+		}
+		if rules {
+			fmt.Fprint(tw, "\tRULE")
+		}
+		fmt.Fprintln(tw)
+		for _, ch := range channels {
+			name := "#" + ch.Name
+			if ch.Muted {
+				name += " (muted)"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s", ch.ID, name, ch.Type, ch.NumMembers, formatLastSeen(ch), formatSource(ch))
+			if self {
+				fmt.Fprintf(tw, "\t%s", formatMyLastSeen(ch))
+			}
+			fmt.Fprintf(tw, "\t%s\t%s\t%s\t%s\t%s", formatCreated(ch), orDash(ch.Creator), formatFlags(ch), formatText(ch.Topic), formatText(ch.Purpose))
+			if posters {
+				fmt.Fprintf(tw, "\t%s", formatTopPosters(ch.TopPosters))
+			}
+			if rules {
+				fmt.Fprintf(tw, "\t%s", formatRule(ch.Rule))
+			}
+			fmt.Fprintln(tw)
+		}
+		return tw.Flush()
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+func formatSource(ch slack.ChannelInfo) string {
+	if ch.Undetermined() {
+		return "error"
+	}
+	if ch.ActivitySource == "" {
+		return "-"
+	}
+	return strings.ReplaceAll(ch.ActivitySource, "_", " ")
+}
+
+func formatLastSeen(ch slack.ChannelInfo) string {
+	if ch.Undetermined() {
+		return "could not determine"
+	}
+	if ch.LastSeen.IsZero() {
+		return "No messages"
+	}
+	return ch.LastSeen.Format("2006-01-02 15:04:05")
+}
+
+func formatTopPosters(posters []slack.PosterCount) string {
+	if len(posters) == 0 {
+		return "-"
+	}
+	parts := make([]string, 0, len(posters))
+	for _, p := range posters {
+		name := p.Name
+		if name == "" {
+			name = p.User
+		}
+		parts = append(parts, fmt.Sprintf("%s (%d)", name, p.Messages))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatCreated(ch slack.ChannelInfo) string {
+	if ch.Created.IsZero() {
+		return "-"
+	}
+	return ch.Created.Format("2006-01-02")
+}
+
+// formatFlags lists the general and shared flags of a channel, e.g. "general,shared"
+func formatFlags(ch slack.ChannelInfo) string {
+	var flags []string
+	if ch.IsGeneral {
+		flags = append(flags, "general")
+	}
+	if ch.IsShared {
+		flags = append(flags, "shared")
+	}
+	if ch.IsExtShared {
+		flags = append(flags, "ext-shared")
+	}
+	return orDash(strings.Join(flags, ","))
+}
+
+// formatText fits a topic or purpose into a table cell: one line of at most 40 characters
+func formatText(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > 40 {
+		text = string(runes[:37]) + "..."
+	}
+	return orDash(text)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func formatRule(rule *slack.RuleMatch) string {
+	if rule == nil {
+		return "-"
+	}
+	return rule.String()
+}
+
+func formatMyLastSeen(ch slack.ChannelInfo) string {
+	if ch.MyLastSeen.IsZero() {
+		return "None since cutoff"
+	}
+	return ch.MyLastSeen.Format("2006-01-02 15:04:05")
+}
+
+// writeLeaveReport prints the per-channel outcome of a leave run
+func writeLeaveReport(w io.Writer, report *slack.LeaveReport) {
+	done := report.Action.Done()
+	done = strings.ToUpper(done[:1]) + done[1:]
+	fmt.Fprintf(w, "\n%s %d, failed %d, skipped %d", done, len(report.Succeeded), len(report.Failed), len(report.Skipped))
+	if len(report.Muted) > 0 {
+		fmt.Fprintf(w, ", muted by rule %d", len(report.Muted))
+	}
+	fmt.Fprintln(w)
+	for _, ch := range report.Succeeded {
+		fmt.Fprintf(w, "  ✅ #%s\n", ch.Name)
+	}
+	for _, ch := range report.Muted {
+		fmt.Fprintf(w, "  🔇 #%s (rule %s)\n", ch.Name, ch.Rule.Name)
+	}
+	for _, f := range report.Failed {
+		fmt.Fprintf(w, "  ❌ #%s: %v\n", f.Channel.Name, f.Err)
+	}
+	for _, sk := range report.Skipped {
+		fmt.Fprintf(w, "  ⏭️  #%s: %s\n", sk.Channel.Name, sk.Reason)
+	}
+	if report.JournalErr != nil {
+		fmt.Fprintf(w, "⚠️  %v\n", report.JournalErr)
+	}
+}
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"workspace-channels-cleaner/slack"
+)
+
+func TestWriteChannelsNDJSONAndTable(t *testing.T) {
+	lastSeen := time.Date(2024, 3, 1, 12, 0, 0, 0, time.Local)
+	channels := []slack.ChannelInfo{
+		{ID: "C1", Name: "old-project", Type: "public", NumMembers: 3, LastSeen: lastSeen, ActivitySource: slack.SourceThreadReply},
+		{ID: "C2", Name: "empty", Type: "private", IsShared: true},
+	}
+
+	var out bytes.Buffer
+	if err := writeChannels(&out, "ndjson", channels, false); err != nil {
+		t.Fatalf("ndjson: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != len(channels) {
+		t.Fatalf("ndjson wrote %d lines, want one per channel:\n%s", len(lines), out.String())
+	}
+	for i, line := range lines {
+		var ch slack.ChannelInfo
+		if err := json.Unmarshal([]byte(line), &ch); err != nil || ch.ID != channels[i].ID {
+			t.Errorf("ndjson line %d is %q (%v), want the object for %s", i, line, err, channels[i].ID)
+		}
+	}
+
+	out.Reset()
+	if err := writeChannels(&out, "table", channels, false); err != nil {
+		t.Fatalf("table: %v", err)
+	}
+	rows := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(rows) != 3 {
+		t.Fatalf("table has %d rows, want a header and 2 channels:\n%s", len(rows), out.String())
+	}
+	want := [][]string{
+		{"ID", "CHANNEL", "TYPE", "MEMBERS", "LAST", "ACTIVITY", "SOURCE", "CREATED", "CREATOR", "FLAGS", "TOPIC", "PURPOSE"},
+		{"C1", "#old-project", "public", "3", "2024-03-01", "12:00:00", "thread", "reply", "-", "-", "-", "-", "-"},
+		{"C2", "#empty", "private", "0", "No", "messages", "-", "-", "-", "shared", "-", "-"},
+	}
+	for i, row := range rows {
+		if got := strings.Fields(row); strings.Join(got, " ") != strings.Join(want[i], " ") {
+			t.Errorf("table row %d is %q, want %q", i, got, want[i])
+		}
+	}
+	// Columns line up under the header
+	if col := strings.Index(rows[0], "TYPE"); strings.Index(rows[1], "public") != col || strings.Index(rows[2], "private") != col {
+		t.Errorf("TYPE column not aligned:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := writeChannels(&out, "table", channels, true); err != nil {
+		t.Fatalf("table: %v", err)
+	}
+	if header := strings.Join(strings.Fields(strings.SplitN(out.String(), "\n", 2)[0]), " "); !strings.Contains(header, "SOURCE MY LAST ACTIVITY CREATED") {
+		t.Errorf("self table header is %q, want MY LAST ACTIVITY after SOURCE", header)
+	}
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"workspace-channels-cleaner/config"
+	"workspace-channels-cleaner/slack"
+)
+
+// runRejoin lists the leave journal or rejoins selected entries from it
+func runRejoin(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("rejoin", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	all := fs.Bool("all", false, "rejoin every channel in the journal that hasn't been rejoined")
+	journalPath := fs.String("journal", slack.DefaultJournalPath, "path to the leave journal")
+	if err := fs.Parse(args); err != nil {
+		return ExitError
+	}
+
+	entries, err := slack.LoadJournal(*journalPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "❌ %v\n", err)
+		return ExitError
+	}
+	pending := slack.PendingJournalEntries(entries)
+
+	if !*all && fs.NArg() == 0 {
+		return listJournal(stdout, pending)
+	}
+
+	selected := pending
+	if !*all {
+		selected, err = selectJournalEntries(pending, fs.Args())
+		if err != nil {
+			fmt.Fprintf(stderr, "❌ %v\n", err)
+			return ExitError
+		}
+	}
+	if len(selected) == 0 {
+		fmt.Fprintln(stdout, "Nothing to rejoin.")
+		return ExitOK
+	}
+
+	appConfig, err := config.LoadConfig(config.GetConfigPath())
+	if err != nil {
+		fmt.Fprintf(stderr, "❌ %v\n", err)
+		return ExitError
+	}
+	if !requireToken(stderr) {
+		return ExitError
+	}
+	cleaner := slack.NewCleanerFromConfig(config.GetWorkspaceToken(), appConfig)
+	cleaner.JournalPath = *journalPath
+
+	code := ExitOK
+	for _, r := range cleaner.RejoinChannels(selected) {
+		if r.Err == nil {
+			fmt.Fprintf(stdout, "✅ Rejoined #%s\n", r.Entry.Name)
+			continue
+		}
+		code = ExitError
+		if errors.Is(r.Err, slack.ErrPrivateRejoin) {
+			fmt.Fprintf(stdout, "🔒 #%s is private: %v\n", r.Entry.Name, r.Err)
+			continue
+		}
+		fmt.Fprintf(stdout, "❌ Could not rejoin #%s: %v\n", r.Entry.Name, r.Err)
+	}
+	return code
+}
+
+func listJournal(w io.Writer, pending []slack.JournalEntry) int {
+	if len(pending) == 0 {
+		fmt.Fprintln(w, "The leave journal is empty.")
+		return ExitOK
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tCHANNEL\tTYPE\tLEFT AT")
+	for _, e := range pending {
+		fmt.Fprintf(tw, "%s\t#%s\t%s\t%s\n", e.ChannelID, e.Name, e.Type, e.LeftAt.Local().Format("2006-01-02 15:04:05"))
+	}
+	tw.Flush()
+	fmt.Fprintln(w, "\nRun 'rejoin <id|name>...' or 'rejoin --all' to rejoin.")
+	return ExitOK
+}
+
+// selectJournalEntries picks pending entries by channel ID or name
+func selectJournalEntries(pending []slack.JournalEntry, keys []string) ([]slack.JournalEntry, error) {
+	var selected []slack.JournalEntry
+	for _, key := range keys {
+		key = strings.TrimPrefix(key, "#")
+		found := false
+		for _, e := range pending {
+			if e.ChannelID == key || e.Name == key {
+				selected = append(selected, e)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("#%s is not in the leave journal", key)
+		}
+	}
+	return selected, nil
+}
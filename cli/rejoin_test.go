@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"workspace-channels-cleaner/slack"
+	"workspace-channels-cleaner/slack/fakeapi"
+)
+
+func TestRejoinByIDOrName(t *testing.T) {
+	srv := newTestWorkspace(t)
+	for _, ch := range []struct{ id, name string }{{"C1", "alpha"}, {"C2", "beta"}, {"C3", "gamma"}} {
+		left := fakeapi.Channel(ch.id, ch.name, false)
+		left.IsMember = false
+		srv.AddChannel(left)
+	}
+	secret := fakeapi.Channel("C4", "secret", true)
+	secret.IsMember = false
+	srv.AddChannel(secret)
+
+	journal := filepath.Join(t.TempDir(), "journal.json")
+	leftAt := time.Now().UTC()
+	err := slack.SaveJournal(journal, []slack.JournalEntry{
+		{ChannelID: "C1", Name: "alpha", Type: "public", LeftAt: leftAt},
+		{ChannelID: "C2", Name: "beta", Type: "public", LeftAt: leftAt},
+		{ChannelID: "C3", Name: "gamma", Type: "public", LeftAt: leftAt},
+		{ChannelID: "C4", Name: "secret", Type: "private", LeftAt: leftAt},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code, stdout, _ := run(runRejoin, "", "--journal", journal, "C1", "#beta")
+	if code != ExitOK || !srv.IsMember("C1") || !srv.IsMember("C2") || srv.IsMember("C3") {
+		t.Fatalf("rejoin by ID and name exited %d:\n%s", code, stdout)
+	}
+
+	code, stdout, _ = run(runRejoin, "", "--journal", journal)
+	if code != ExitOK || strings.Contains(stdout, "#alpha") || strings.Contains(stdout, "#beta") || !strings.Contains(stdout, "#gamma") {
+		t.Errorf("listing after rejoining exited %d, want only pending channels:\n%s", code, stdout)
+	}
+
+	if code, _, _ := run(runRejoin, "", "--journal", journal, "alpha"); code != ExitError {
+		t.Errorf("rejoining a channel that was rejoined already exited %d, want %d", code, ExitError)
+	}
+
+	code, stdout, _ = run(runRejoin, "", "--journal", journal, "--all")
+	if code != ExitError || !srv.IsMember("C3") || !strings.Contains(stdout, "#secret is private") {
+		t.Errorf("rejoin --all exited %d, want %d with gamma rejoined and secret refused:\n%s", code, ExitError, stdout)
+	}
+}
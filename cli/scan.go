@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"workspace-channels-cleaner/config"
+	"workspace-channels-cleaner/plan"
+	"workspace-channels-cleaner/slack"
+)
+
+// runScan runs a headless stale-channel scan using the saved configuration
+func runScan(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("scan", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "table", "output format: json, ndjson or table")
+	configPath := fs.String("config", config.GetConfigPath(), "path to the configuration file")
+	out := fs.String("out", "", "also write a leave plan to this file")
+	rescan := fs.Bool("rescan", false, "ignore cached results and check every channel")
+	actionName := fs.String("action", string(slack.ActionLeave), "what the plan written by -out does: leave, mute, archive or notice")
+	mutedOnly := fs.Bool("muted", false, "only report channels muted earlier that are still stale")
+	policyExpr := fs.String("policy", "", "report the channels this expression holds for as stale, instead of the configured policy")
+	if err := fs.Parse(args); err != nil {
+		return ExitError
+	}
+	action, err := slack.ParseAction(*actionName)
+	if err != nil {
+		fmt.Fprintf(stderr, "❌ %v\n", err)
+		return ExitError
+	}
+	if !isValidFormat(*format) {
+		fmt.Fprintf(stderr, "❌ unknown format %q (must be json, ndjson or table)\n", *format)
+		return ExitError
+	}
+
+	appConfig, err := config.LoadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "❌ %v\n", err)
+		return ExitError
+	}
+	if *policyExpr != "" {
+		appConfig.Policy = *policyExpr
+	}
+	if err := config.ValidateConfig(appConfig); err != nil {
+		fmt.Fprintf(stderr, "❌ invalid configuration: %v\n", err)
+		return ExitError
+	}
+	if err := checkArchiveStaleness(action, appConfig); err != nil {
+		fmt.Fprintf(stderr, "❌ %v\n", err)
+		return ExitError
+	}
+
+	if !requireToken(stderr) {
+		return ExitError
+	}
+	cleaner := slack.NewCleanerFromConfig(config.GetWorkspaceToken(), appConfig)
+	cleaner.Out = stderr // Keep stdout machine-readable
+	cleaner.ForceRescan = *rescan
+
+	// Ctrl+C stops the scan cleanly instead of killing it mid-request
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	channels, err := cleaner.GetFilteredChannels(ctx)
+	if err != nil {
+		fmt.Fprintf(stderr, "❌ scan failed: %v\n", err)
+		return ExitError
+	}
+
+	if *mutedOnly {
+		channels = mutedChannels(channels)
+	}
+
+	var stale []slack.ChannelInfo
+	undetermined := 0
+	for _, ch := range channels {
+		if ch.Undetermined() {
+			fmt.Fprintf(stderr, "⚠️  #%s: could not determine activity: %s\n", ch.Name, ch.Error)
+			undetermined++
+			continue
+		}
+		stale = append(stale, ch)
+	}
+
+	if *out != "" {
+		// Only channels known to be stale belong in a plan
+		if err := plan.Save(*out, plan.New(appConfig, cleaner.Cutoff, action, stale)); err != nil {
+			fmt.Fprintf(stderr, "❌ %v\n", err)
+			return ExitError
+		}
+		fmt.Fprintf(stderr, "📝 Wrote %s plan for %d channel(s) to %s\n", action, len(stale), *out)
+	}
+
+	if err := writeChannels(stdout, *format, channels, cleaner.SelfActivity); err != nil {
+		fmt.Fprintf(stderr, "❌ failed to write output: %v\n", err)
+		return ExitError
+	}
+
+	// An incomplete scan must not look like a clean one
+	if undetermined > 0 {
+		return ExitError
+	}
+	if len(stale) == 0 {
+		return ExitOK
+	}
+	return ExitStale
+}
+
+// mutedChannels keeps the channels muted by an earlier run
+func mutedChannels(channels []slack.ChannelInfo) []slack.ChannelInfo {
+	var muted []slack.ChannelInfo
+	for _, ch := range channels {
+		if ch.Muted {
+			muted = append(muted, ch)
+		}
+	}
+	return muted
+}
+
+// checkArchiveStaleness refuses to archive or notify channels judged by the user's
+// own activity: these affect everyone, so a channel must be stale for everyone
+func checkArchiveStaleness(action slack.Action, appConfig *config.AppConfig) error {
+	if action.ForEveryone() && appConfig.Staleness == config.StalenessSelf {
+		return fmt.Errorf("%s needs %q staleness; channels stale only for you may still be in use", action, config.StalenessChannel)
+	}
+	return nil
+}
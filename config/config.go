@@ -4,25 +4,158 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
+	"text/template"
+
+	"workspace-channels-cleaner/policy"
 )
 
 // AppConfig holds the application configuration
 type AppConfig struct {
-	Days    int      `json:"days"`
-	Limit   int      `json:"limit"`
-	Types   []string `json:"types"`
-	Verbose bool     `json:"verbose"`
-	Keyword string   `json:"keyword"`
+	Days     int            `json:"days"`
+	Limit    int            `json:"limit"`
+	Types    []string       `json:"types"`
+	Verbose  bool           `json:"verbose"`
+	Activity ActivityConfig `json:"activity"`
+
+	// Include and Exclude filter channels by name. Entries are substrings, globs
+	// (proj-*-archive) or regexes (re:^inc-\d+$) and ignore case; a channel must
+	// match an include entry, if there are any, and no exclude entry.
+	Include []string `json:"include"`
+	Exclude []string `json:"exclude"`
+
+	// Keyword is the single substring filter of older configurations; MigrateKeyword
+	// moves it into Include
+	Keyword string `json:"keyword,omitempty"`
+
+	// IncludeEmpty reports channels that have no message counting as activity at all
+	IncludeEmpty bool `json:"include_empty"`
+
+	// IncludeThreads counts replies in threads under recent messages as activity
+	IncludeThreads bool `json:"include_threads"`
+
+	// Staleness selects whose activity decides staleness: StalenessChannel or StalenessSelf
+	Staleness string `json:"staleness"`
+
+	// Cache keeps scan results between runs so later scans only re-check some channels
+	Cache CacheConfig `json:"cache"`
+
+	// Notice is the message posted to channels before they are archived
+	Notice NoticeConfig `json:"notice"`
+
+	// Export saves each channel's history locally before it is left
+	Export ExportConfig `json:"export"`
+
+	// Rules override Days and the action for the channels they match
+	Rules []RuleConfig `json:"rules"`
+
+	// Policy is an expression that decides which channels are stale in place of
+	// Days and the rules' thresholds, e.g. "days_idle > 45 && members < 5";
+	// empty judges channels by Days and the rules
+	Policy string `json:"policy"`
+}
+
+// RuleConfig sets the staleness threshold and action for matching channels.
+// Rules are tried in order and the first match wins; channels no rule matches
+// use the global days.
+type RuleConfig struct {
+	Name    string `json:"name,omitempty"`    // Shown in the results; defaults to the pattern
+	Pattern string `json:"pattern,omitempty"` // Channel pattern as in the skip list; empty matches every name
+	Type    string `json:"type,omitempty"`    // "public" or "private"; empty matches both
+	Days    int    `json:"days,omitempty"`    // Staleness threshold; 0 keeps the global days
+	Action  string `json:"action,omitempty"`  // One of the Rule* actions; empty means RuleLeave
+}
+
+// Rule actions
+const (
+	RuleLeave  = "leave"  // Report the channel and leave it
+	RuleMute   = "mute"   // Report the channel and mute it instead of leaving
+	RuleIgnore = "ignore" // Never report the channel
+)
+
+// ExportConfig controls exporting channel history before leaving
+type ExportConfig struct {
+	Enabled bool   `json:"enabled"`
+	Dir     string `json:"dir"` // Exports go to a dated subdirectory of Dir
+}
+
+// NoticeConfig controls the pre-archive notice workflow
+type NoticeConfig struct {
+	Message   string `json:"message"`    // text/template with the fields of slack.NoticeData
+	GraceDays int    `json:"grace_days"` // Days to wait for objections before a channel may be archived
+}
+
+// DefaultNoticeMessage is posted when no notice message is configured
+const DefaultNoticeMessage = "👋 Nobody has posted in #{{.Channel}} for {{.Days}} days, so it will be archived on {{.ArchiveDate}}. Post here or reply to this message to keep it."
+
+// CacheConfig controls the local scan cache
+type CacheConfig struct {
+	Enabled    bool `json:"enabled"`
+	TTLHours   int  `json:"ttl_hours"`   // How long a cached result is trusted
+	MarginDays int  `json:"margin_days"` // Results this close to the cutoff are always re-checked
+}
+
+// Staleness modes
+const (
+	StalenessChannel = "channel" // A channel is stale when nobody has been active in it
+	StalenessSelf    = "self"    // A channel is stale when the authenticated user hasn't posted or reacted in it
+)
+
+// ActivityConfig defines which messages count as channel activity
+type ActivityConfig struct {
+	IgnoreSubtypes []string `json:"ignore_subtypes"` // Message subtypes that don't count, e.g. bot_message
+	IgnoreUsers    []string `json:"ignore_users"`    // User or bot IDs whose messages don't count
+}
+
+// DefaultIgnoredSubtypes are the message subtypes that don't count as human activity
+var DefaultIgnoredSubtypes = []string{
+	"bot_message",
+	"channel_join",
+	"channel_leave",
+	"channel_topic",
+	"channel_purpose",
+	"channel_name",
+	"channel_archive",
+	"channel_unarchive",
+	"group_join",
+	"group_leave",
+	"group_topic",
+	"group_purpose",
+	"group_name",
+	"pinned_item",
+	"unpinned_item",
+	"reminder_add",
 }
 
 // DefaultConfig returns the default configuration
 func DefaultConfig() *AppConfig {
 	return &AppConfig{
-		Days:    30,
-		Limit:   30,
-		Types:   []string{"public"},
-		Verbose: false,
-		Keyword: "",
+		Days:         30,
+		Limit:        30,
+		Types:        []string{"public"},
+		Verbose:      false,
+		Include:      []string{},
+		Exclude:      []string{},
+		IncludeEmpty: false,
+		Staleness:    StalenessChannel,
+		Cache: CacheConfig{
+			Enabled:    false,
+			TTLHours:   24,
+			MarginDays: 3,
+		},
+		Notice: NoticeConfig{
+			Message:   DefaultNoticeMessage,
+			GraceDays: 14,
+		},
+		Export: ExportConfig{
+			Enabled: false,
+			Dir:     "exports",
+		},
+		Rules: []RuleConfig{},
+		Activity: ActivityConfig{
+			IgnoreSubtypes: append([]string(nil), DefaultIgnoredSubtypes...),
+			IgnoreUsers:    []string{},
+		},
 	}
 }
 
@@ -34,11 +167,14 @@ func LoadConfig(path string) (*AppConfig, error) {
 		return DefaultConfig(), nil
 	}
 
-	var config AppConfig
+	// Start from the defaults so settings missing from the file keep their default value
+	config := *DefaultConfig()
 	if err := json.Unmarshal(data, &config); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
+	config.MigrateKeyword()
+
 	// Validate and set defaults for missing values
 	if config.Days <= 0 {
 		config.Days = 30
@@ -49,10 +185,43 @@ func LoadConfig(path string) (*AppConfig, error) {
 	if len(config.Types) == 0 {
 		config.Types = []string{"public"}
 	}
+	if config.Staleness == "" {
+		config.Staleness = StalenessChannel
+	}
+	if config.Cache.TTLHours <= 0 {
+		config.Cache.TTLHours = 24
+	}
+	if config.Cache.MarginDays < 0 {
+		config.Cache.MarginDays = 0
+	}
+	if config.Notice.Message == "" {
+		config.Notice.Message = DefaultNoticeMessage
+	}
+	if config.Notice.GraceDays <= 0 {
+		config.Notice.GraceDays = 14
+	}
+	if config.Export.Dir == "" {
+		config.Export.Dir = "exports"
+	}
 
 	return &config, nil
 }
 
+// MigrateKeyword moves the Keyword of an older configuration into Include
+func (c *AppConfig) MigrateKeyword() {
+	if c.Keyword == "" {
+		return
+	}
+	for _, entry := range c.Include {
+		if entry == c.Keyword {
+			c.Keyword = ""
+			return
+		}
+	}
+	c.Include = append(c.Include, c.Keyword)
+	c.Keyword = ""
+}
+
 // SaveConfig saves configuration to file
 func SaveConfig(path string, config *AppConfig) error {
 	data, err := json.MarshalIndent(config, "", "  ")
@@ -86,6 +255,52 @@ func ValidateConfig(config *AppConfig) error {
 			return fmt.Errorf("invalid channel type: %s (must be 'public' or 'private')", t)
 		}
 	}
+
+	if config.Staleness != StalenessChannel && config.Staleness != StalenessSelf {
+		return fmt.Errorf("invalid staleness mode: %s (must be '%s' or '%s')", config.Staleness, StalenessChannel, StalenessSelf)
+	}
+	if _, err := template.New("notice").Parse(config.Notice.Message); err != nil {
+		return fmt.Errorf("invalid notice message: %w", err)
+	}
+	if strings.TrimSpace(config.Policy) != "" {
+		if _, err := policy.Parse(config.Policy); err != nil {
+			return fmt.Errorf("invalid policy: %w", err)
+		}
+	}
+	for _, entry := range config.Include {
+		if err := ValidateIncludeEntry(entry); err != nil {
+			return err
+		}
+	}
+	for i, rule := range config.Rules {
+		if err := validateRule(rule); err != nil {
+			return fmt.Errorf("invalid rule %d: %w", i+1, err)
+		}
+	}
 	
 	return nil
-} 
\ No newline at end of file
+}
+
+// ValidateIncludeEntry rejects include entries that look like exclusions: a leading
+// "-" is matched literally, so "-social" would select #eng-social rather than leave it out
+func ValidateIncludeEntry(entry string) error {
+	if strings.HasPrefix(strings.TrimSpace(entry), "-") {
+		return fmt.Errorf("include entry %q starts with '-'; to leave channels out, add %q to exclude instead", entry, strings.TrimPrefix(strings.TrimSpace(entry), "-"))
+	}
+	return nil
+}
+
+// validateRule checks the fields of a rule that don't need the channel pattern syntax
+func validateRule(rule RuleConfig) error {
+	if rule.Type != "" && rule.Type != "public" && rule.Type != "private" {
+		return fmt.Errorf("invalid channel type: %s (must be 'public' or 'private')", rule.Type)
+	}
+	if rule.Days < 0 {
+		return fmt.Errorf("days must not be negative")
+	}
+	switch rule.Action {
+	case "", RuleLeave, RuleMute, RuleIgnore:
+		return nil
+	}
+	return fmt.Errorf("invalid action: %s (must be '%s', '%s' or '%s')", rule.Action, RuleLeave, RuleMute, RuleIgnore)
+}
@@ -16,6 +16,12 @@ func GetWorkspaceToken() string {
 	return os.Getenv("WORKSPACE_API_TOKEN")
 }
 
+// GetWorkspaceAPIURL returns the API base URL from environment; empty means the
+// real workspace API
+func GetWorkspaceAPIURL() string {
+	return os.Getenv("WORKSPACE_API_URL")
+}
+
 // ValidateToken checks if the workspace token is set
 func ValidateToken() error {
 	token := GetWorkspaceToken()
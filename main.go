@@ -19,6 +19,7 @@ import (
 	"log"
 	"os"
 
+	"workspace-channels-cleaner/cli"
 	"workspace-channels-cleaner/config"
 	"workspace-channels-cleaner/model"
 
@@ -30,6 +31,12 @@ func main() {
 		log.Printf("Warning: Could not load .env file: %v", err)
 	}
 
+	// Subcommands run headless, e.g. from cron or CI, and check the token only
+	// where they call the API
+	if len(os.Args) > 1 {
+		os.Exit(cli.Run(os.Args[1:]))
+	}
+
 	if err := config.ValidateToken(); err != nil {
 		fmt.Printf("❌ %s\n", err.Error())
 		fmt.Println("Please set your SLACK_API_TOKEN in the .env file or environment variables.")
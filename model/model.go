@@ -1,11 +1,15 @@
 package model
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"workspace-channels-cleaner/config"
+	"workspace-channels-cleaner/policy"
 	"workspace-channels-cleaner/slack"
 
 	"github.com/charmbracelet/bubbletea"
@@ -24,6 +28,8 @@ const (
 	ConfirmationScreen
 	SkipListScreen
 	LoadingScreen
+	JournalScreen
+	LeaveSummaryScreen
 )
 
 // model represents the main application model
@@ -48,22 +54,55 @@ type model struct {
 	useSimpleView bool // Toggle between table and simple list view
 	
 	// Skip list
-	skipList map[string]bool
+	skipList *slack.SkipList
 	skipCursor int
 	skipChoices []string
 	skipListOffset int // For pagination
-	skipListMode string // "view", "add", "remove"
+	skipListMode string // "view", "add", "remove", "expired"
 	skipListInput string // For adding new channels
+	skipAddStep int // 0: pattern, 1: reason, 2: expiry
+	skipAddEntry slack.SkipEntry // Entry being built by the add steps
+	skipExpiredCursor int
+	protected []slack.ProtectedChannel // Channels the skip list kept out of the last scan
 	
 	// Config editing
 	configMode string // "view", "edit"
 	configCursor int
 	configInput string
-	editingField string // "days", "limit", "types", "verbose", "keyword"
+	editingField string // "days", "limit", "types", "verbose", "include", "exclude", ...
+	listCursor   int    // Entry under the cursor while editing the include or exclude list
+	listAdding   bool   // configInput holds a new list entry being typed
+	
+	// Pending confirmation; archiving must be confirmed by typing "archive"
+	confirmAction slack.Action
+	confirmInput string
+	
+	// Outcome of the last leave or archive run
+	leaveReport *slack.LeaveReport
+	
+	// Rejoin journal
+	journal []slack.JournalEntry // Entries not rejoined yet
+	journalCursor int
+	journalOffset int
+	journalSelected map[int]struct{}
+	rejoinResults []slack.RejoinResult // Outcome of the last rejoin
 	
 	// Loading
 	loadingMsg string
 	
+	// Running scan; scanEvents is nil when no scan is running
+	scanCancel   context.CancelFunc
+	scanEvents   chan tea.Msg
+	scanProgress slack.ScanProgress
+	scanStarted  time.Time
+	scanStopped  bool // The scan was cancelled after results started arriving
+	forceRescan  bool // Ignore cached results in the next scan
+	
+	// Policy input on the filter screen
+	editingPolicy bool
+	policyInput   string
+	policyErr     error // Why the policy being entered or configured doesn't parse
+	
 	// Error handling
 	err error
 	
@@ -165,6 +204,7 @@ func InitialModel() model {
 			"⚙️  Configuration",
 			"📝 Edit Skip List",
 			"🚪 Leave Channels",
+			"↩️  Rejoin Left Channels",
 			"❌ Exit",
 		},
 		selected: make(map[int]struct{}),
@@ -192,14 +232,29 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case errorMsg:
 		m.err = msg
 		return m, nil
-	case channelsLoadedMsg:
-		m.channels = msg.channels
-		m.resultsOffset = 0 // Reset pagination
-		m.cursor = 0 // Reset cursor
-		m.state = ResultsScreen
-		return m, nil
+	case scanEventMsg:
+		if msg.events != m.scanEvents {
+			// Keep draining an abandoned scan so its goroutine can finish
+			if isFinalScanMsg(msg.msg) {
+				return m, nil
+			}
+			return m, waitForScanEvent(msg.events)
+		}
+		return m.handleScanEvent(msg.msg)
+	case scanTickMsg:
+		if m.scanEvents == nil {
+			return m, nil
+		}
+		return m, scanTick() // Keep the ETA and rate limit countdown moving
 	case channelsLeftMsg:
-		m.state = MainMenu
+		m.leaveReport = msg.report
+		m.selected = make(map[int]struct{})
+		m.state = LeaveSummaryScreen
+		return m, nil
+	case channelsRejoinedMsg:
+		updated, _ := m.loadJournal()
+		m = updated.(model)
+		m.rejoinResults = msg.results
 		return m, nil
 	}
 	return m, nil
@@ -220,6 +275,34 @@ func (m model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.handleConfirmationScreen(msg)
 	case SkipListScreen:
 		return m.handleSkipListScreen(msg)
+	case JournalScreen:
+		return m.handleJournalScreen(msg)
+	case LeaveSummaryScreen:
+		return m.handleLeaveSummaryScreen(msg)
+	case LoadingScreen:
+		return m.handleLoadingScreen(msg)
+	}
+	return m, nil
+}
+
+func (m model) handleLoadingScreen(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		if m.scanCancel != nil {
+			m.scanCancel()
+		}
+		return m, tea.Quit
+	case "esc", "q", "enter":
+		if m.scanCancel != nil {
+			// The scan reports scanCancelledMsg once its workers have stopped
+			m.scanCancel()
+			m.loadingMsg = "Cancelling scan..."
+			return m, nil
+		}
+		if m.err != nil {
+			m.err = nil
+			m.state = MainMenu
+		}
 	}
 	return m, nil
 }
@@ -256,7 +339,9 @@ func (m model) handleMenuSelection() (tea.Model, tea.Cmd) {
 		return m.loadSkipList()
 	case 3: // Leave Channels
 		return m.loadChannels()
-	case 4: // Exit
+	case 4: // Rejoin Left Channels
+		return m.loadJournal()
+	case 5: // Exit
 		return m, tea.Quit
 	}
 	return m, nil
@@ -306,7 +391,7 @@ func (m model) handleConfigEdit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.configCursor--
 		}
 	case "down", "j":
-		if m.configCursor < 4 { // 5 fields: days, limit, types, verbose, keyword
+		if m.configCursor < 9 { // 10 fields: days, limit, types, verbose, include, exclude, include empty, include threads, staleness, export
 			m.configCursor++
 		}
 	case "enter":
@@ -337,14 +422,35 @@ func (m model) startConfigFieldEdit() (tea.Model, tea.Cmd) {
 	case 3: // Verbose
 		m.editingField = "verbose"
 		m.configInput = fmt.Sprintf("%t", m.config.Verbose)
-	case 4: // Keyword
-		m.editingField = "keyword"
-		m.configInput = m.config.Keyword
+	case 4, 5: // Include, exclude
+		m.editingField = "include"
+		if m.configCursor == 5 {
+			m.editingField = "exclude"
+		}
+		m.listCursor = 0
+		m.listAdding = false
+		m.configInput = ""
+	case 6: // Include empty
+		m.editingField = "include empty"
+		m.configInput = fmt.Sprintf("%t", m.config.IncludeEmpty)
+	case 7: // Include threads
+		m.editingField = "include threads"
+		m.configInput = fmt.Sprintf("%t", m.config.IncludeThreads)
+	case 8: // Staleness
+		m.editingField = "staleness"
+		m.configInput = m.config.Staleness
+	case 9: // Export before leave
+		m.editingField = "export before leave"
+		m.configInput = fmt.Sprintf("%t", m.config.Export.Enabled)
 	}
 	return m, nil
 }
 
 func (m model) handleConfigFieldEdit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.editingField == "include" || m.editingField == "exclude" {
+		return m.handleConfigListEdit(msg)
+	}
+	
 	switch msg.String() {
 	case "ctrl+c", "q":
 		m.editingField = ""
@@ -364,6 +470,96 @@ func (m model) handleConfigFieldEdit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// handleConfigListEdit edits the include or exclude list: 'a' adds an entry, which
+// must parse, and 'd' deletes the one under the cursor
+func (m model) handleConfigListEdit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	list := m.configList()
+	
+	if m.listAdding {
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			m.listAdding = false
+			m.configInput = ""
+			m.err = nil
+		case "enter":
+			entry := strings.TrimSpace(m.configInput)
+			if entry == "" {
+				m.listAdding = false
+				return m, nil
+			}
+			// Keep the input so an invalid entry can be fixed
+			if _, err := slack.ParseNamePattern(entry); err != nil {
+				m.err = err
+				return m, nil
+			}
+			if m.editingField == "include" {
+				if err := config.ValidateIncludeEntry(entry); err != nil {
+					m.err = err
+					return m, nil
+				}
+			}
+			m.setConfigList(append(list[:len(list):len(list)], entry))
+			m.listCursor = len(list)
+			m.listAdding = false
+			m.configInput = ""
+			m.err = nil
+		case "backspace":
+			if len(m.configInput) > 0 {
+				m.configInput = m.configInput[:len(m.configInput)-1]
+			}
+		default:
+			if len(msg.String()) == 1 {
+				m.configInput += msg.String()
+			}
+		}
+		return m, nil
+	}
+	
+	switch msg.String() {
+	case "ctrl+c", "q", "esc", "enter":
+		m.editingField = ""
+		m.listCursor = 0
+		m.err = nil
+	case "up", "k":
+		if m.listCursor > 0 {
+			m.listCursor--
+		}
+	case "down", "j":
+		if m.listCursor < len(list)-1 {
+			m.listCursor++
+		}
+	case "a":
+		m.listAdding = true
+		m.configInput = ""
+	case "d", "delete":
+		if m.listCursor < len(list) {
+			rest := append([]string{}, list[:m.listCursor]...)
+			m.setConfigList(append(rest, list[m.listCursor+1:]...))
+			if m.listCursor > 0 && m.listCursor >= len(list)-1 {
+				m.listCursor--
+			}
+		}
+	}
+	return m, nil
+}
+
+// configList returns the name filter list being edited
+func (m model) configList() []string {
+	if m.editingField == "exclude" {
+		return m.config.Exclude
+	}
+	return m.config.Include
+}
+
+// setConfigList replaces the name filter list being edited
+func (m model) setConfigList(list []string) {
+	if m.editingField == "exclude" {
+		m.config.Exclude = list
+	} else {
+		m.config.Include = list
+	}
+}
+
 func (m model) saveConfigField() (tea.Model, tea.Cmd) {
 	switch m.editingField {
 	case "days":
@@ -392,8 +588,28 @@ func (m model) saveConfigField() (tea.Model, tea.Cmd) {
 		} else if m.configInput == "false" {
 			m.config.Verbose = false
 		}
-	case "keyword":
-		m.config.Keyword = m.configInput
+	case "include empty":
+		if m.configInput == "true" {
+			m.config.IncludeEmpty = true
+		} else if m.configInput == "false" {
+			m.config.IncludeEmpty = false
+		}
+	case "include threads":
+		if m.configInput == "true" {
+			m.config.IncludeThreads = true
+		} else if m.configInput == "false" {
+			m.config.IncludeThreads = false
+		}
+	case "staleness":
+		if m.configInput == config.StalenessChannel || m.configInput == config.StalenessSelf {
+			m.config.Staleness = m.configInput
+		}
+	case "export before leave":
+		if m.configInput == "true" {
+			m.config.Export.Enabled = true
+		} else if m.configInput == "false" {
+			m.config.Export.Enabled = false
+		}
 	}
 	
 	m.editingField = ""
@@ -402,12 +618,65 @@ func (m model) saveConfigField() (tea.Model, tea.Cmd) {
 }
 
 func (m model) handleFilterScreen(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.editingPolicy {
+		return m.handlePolicyInput(msg)
+	}
+	
 	switch msg.String() {
 	case "ctrl+c", "q":
 		m.state = MainMenu
+		m.policyErr = nil
 		return m, nil
 	case "enter":
+		// A broken policy in app.json would only fail the scan once it started
+		if strings.TrimSpace(m.config.Policy) != "" {
+			if _, err := policy.Parse(m.config.Policy); err != nil {
+				m.policyErr = err
+				return m, nil
+			}
+		}
+		m.policyErr = nil
 		return m.startChannelSearch()
+	case "r":
+		m.forceRescan = !m.forceRescan
+	case "p":
+		m.editingPolicy = true
+		m.policyInput = m.config.Policy
+		m.policyErr = nil
+	}
+	return m, nil
+}
+
+// handlePolicyInput edits the policy expression; it only replaces the configured
+// one once it parses
+func (m model) handlePolicyInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "esc":
+		m.editingPolicy = false
+		m.policyInput = ""
+		m.policyErr = nil
+	case "enter":
+		input := strings.TrimSpace(m.policyInput)
+		if input != "" {
+			if _, err := policy.Parse(input); err != nil {
+				m.policyErr = err
+				return m, nil
+			}
+		}
+		m.config.Policy = input
+		m.editingPolicy = false
+		m.policyInput = ""
+		m.policyErr = nil
+	case "backspace":
+		if len(m.policyInput) > 0 {
+			m.policyInput = m.policyInput[:len(m.policyInput)-1]
+		}
+		m.policyErr = nil
+	default:
+		if len(msg.String()) == 1 {
+			m.policyInput += msg.String()
+			m.policyErr = nil
+		}
 	}
 	return m, nil
 }
@@ -415,8 +684,18 @@ func (m model) handleFilterScreen(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m model) handleResultsScreen(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "ctrl+c", "q":
+		if m.scanCancel != nil {
+			// Abandon the scan; its remaining messages are drained and ignored
+			m.scanCancel()
+			m = m.finishScan()
+		}
 		m.state = MainMenu
 		return m, nil
+	case "esc":
+		if m.scanCancel != nil {
+			// Keep what has been found so far; the scan reports scanCancelledMsg
+			m.scanCancel()
+		}
 	case "up", "k":
 		if m.cursor > 0 {
 			m.cursor--
@@ -436,11 +715,37 @@ func (m model) handleResultsScreen(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case " ":
 		if _, ok := m.selected[m.cursor]; ok {
 			delete(m.selected, m.cursor)
-		} else {
+		} else if m.cursor < len(m.channels) && !m.channels[m.cursor].Undetermined() {
+			// A channel whose activity is unknown may be in use, so it can't be acted on
 			m.selected[m.cursor] = struct{}{}
 		}
 	case "enter":
-		if len(m.selected) > 0 {
+		// Leaving while the scan still streams in results would race with it
+		if len(m.selected) > 0 && m.scanEvents == nil {
+			m.confirmAction = slack.ActionLeave
+			m.state = ConfirmationScreen
+		}
+	case "m":
+		// Muting keeps the membership, so it works in either staleness mode
+		if len(m.selected) > 0 && m.scanEvents == nil {
+			m.confirmAction = slack.ActionMute
+			m.state = ConfirmationScreen
+		}
+	case "M":
+		// Select the channels muted earlier that are still stale, for a final leave
+		for i, ch := range m.channels {
+			if ch.Muted && !ch.Undetermined() {
+				m.selected[i] = struct{}{}
+			}
+		}
+	case "a", "n":
+		// Archiving is for channels stale for everyone, not just for the current user
+		if len(m.selected) > 0 && m.scanEvents == nil && m.config.Staleness != config.StalenessSelf {
+			m.confirmAction = slack.ActionArchive
+			if msg.String() == "n" {
+				m.confirmAction = slack.ActionNotice
+			}
+			m.confirmInput = ""
 			m.state = ConfirmationScreen
 		}
 	case "pageup", "b":
@@ -486,6 +791,9 @@ func (m model) handleResultsScreen(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m model) handleConfirmationScreen(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.confirmAction == slack.ActionArchive {
+		return m.handleArchiveConfirmation(msg)
+	}
 	switch msg.String() {
 	case "ctrl+c", "q":
 		m.state = ResultsScreen
@@ -499,6 +807,37 @@ func (m model) handleConfirmationScreen(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// handleArchiveConfirmation takes the typed "archive" confirmation
+func (m model) handleArchiveConfirmation(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "esc":
+		m.state = ResultsScreen
+		return m, nil
+	case "enter":
+		if m.confirmInput == "archive" {
+			return m.leaveSelectedChannels()
+		}
+	case "backspace":
+		if len(m.confirmInput) > 0 {
+			m.confirmInput = m.confirmInput[:len(m.confirmInput)-1]
+		}
+	default:
+		if len(msg.String()) == 1 {
+			m.confirmInput += msg.String()
+		}
+	}
+	return m, nil
+}
+
+func (m model) handleLeaveSummaryScreen(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "q", "enter":
+		m.state = MainMenu
+		return m, nil
+	}
+	return m, nil
+}
+
 func (m model) handleSkipListScreen(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch m.skipListMode {
 	case "view":
@@ -507,6 +846,8 @@ func (m model) handleSkipListScreen(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.handleSkipListAdd(msg)
 	case "remove":
 		return m.handleSkipListRemove(msg)
+	case "expired":
+		return m.handleSkipListExpired(msg)
 	}
 	return m, nil
 }
@@ -534,6 +875,14 @@ func (m model) handleSkipListView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 	case "a":
 		m.skipListMode = "add"
+		m.skipAddStep = 0
+		m.skipAddEntry = slack.SkipEntry{}
+		return m, nil
+	case "x":
+		if len(m.skipList.Expired(time.Now())) > 0 {
+			m.skipListMode = "expired"
+			m.skipExpiredCursor = 0
+		}
 		return m, nil
 	case "d":
 		if len(m.skipChoices) > 0 {
@@ -548,25 +897,18 @@ func (m model) handleSkipListView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m model) handleSkipListAdd(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "ctrl+c", "q":
+	key := msg.String()
+	// Reason and expiry are free text, so only Esc cancels there
+	if key == "ctrl+c" || key == "esc" || (key == "q" && m.skipAddStep == 0) {
 		m.skipListMode = "view"
 		m.skipListInput = ""
+		m.err = nil
 		return m, nil
+	}
+	
+	switch key {
 	case "enter":
-		if m.skipListInput != "" {
-			// Add to skip list
-			m.skipList[m.skipListInput] = true
-			m.skipChoices = append(m.skipChoices, m.skipListInput)
-			// Save to file
-			err := slack.SaveSkipList("config/skiplist.json", m.skipList)
-			if err != nil {
-				m.err = err
-			}
-		}
-		m.skipListMode = "view"
-		m.skipListInput = ""
-		return m, nil
+		return m.advanceSkipListAdd()
 	case "backspace":
 		if len(m.skipListInput) > 0 {
 			m.skipListInput = m.skipListInput[:len(m.skipListInput)-1]
@@ -579,6 +921,95 @@ func (m model) handleSkipListAdd(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// advanceSkipListAdd stores the current input and moves to the next add step,
+// saving the entry after the last one
+func (m model) advanceSkipListAdd() (tea.Model, tea.Cmd) {
+	input := strings.TrimSpace(m.skipListInput)
+	
+	switch m.skipAddStep {
+	case 0: // Pattern
+		if input == "" {
+			m.skipListMode = "view"
+			return m, nil
+		}
+		// Keep the input so an invalid pattern can be fixed
+		if _, err := slack.ParsePattern(input); err != nil {
+			m.err = err
+			return m, nil
+		}
+		m.skipAddEntry.Pattern = input
+	case 1: // Reason
+		m.skipAddEntry.Reason = input
+	case 2: // Expiry in days, blank for never
+		if input != "" {
+			days, err := strconv.Atoi(input)
+			if err != nil || days < 1 {
+				m.err = fmt.Errorf("expiry must be a number of days (at least 1) or blank")
+				return m, nil
+			}
+			expiresAt := time.Now().AddDate(0, 0, days).UTC()
+			m.skipAddEntry.ExpiresAt = &expiresAt
+		}
+		
+		addedAt := time.Now().UTC()
+		m.skipAddEntry.AddedAt = &addedAt
+		m.skipAddEntry.AddedBy = slack.CurrentUser()
+		if err := m.skipList.AddEntry(m.skipAddEntry); err != nil {
+			m.err = err
+			return m, nil
+		}
+		m.skipChoices = m.skipList.Patterns()
+		m.skipListMode = "view"
+		m.skipListInput = ""
+		m.err = slack.SaveSkipList(slack.DefaultSkipListPath, m.skipList)
+		return m, nil
+	}
+	
+	m.err = nil
+	m.skipAddStep++
+	m.skipListInput = ""
+	return m, nil
+}
+
+func (m model) handleSkipListExpired(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	expired := m.skipList.Expired(time.Now())
+	
+	switch msg.String() {
+	case "ctrl+c", "q":
+		m.skipListMode = "view"
+		return m, nil
+	case "up", "k":
+		if m.skipExpiredCursor > 0 {
+			m.skipExpiredCursor--
+		}
+	case "down", "j":
+		if m.skipExpiredCursor < len(expired)-1 {
+			m.skipExpiredCursor++
+		}
+	case "r", "d":
+		if m.skipExpiredCursor >= len(expired) {
+			return m, nil
+		}
+		entry := expired[m.skipExpiredCursor]
+		if msg.String() == "r" {
+			m.skipList.Renew(entry.Pattern, time.Now().AddDate(0, 0, 30).UTC())
+		} else {
+			m.skipList.Remove(entry.Pattern)
+		}
+		m.skipChoices = m.skipList.Patterns()
+		m.err = slack.SaveSkipList(slack.DefaultSkipListPath, m.skipList)
+		
+		// Leave the review once nothing is expired anymore
+		remaining := len(m.skipList.Expired(time.Now()))
+		if remaining == 0 {
+			m.skipListMode = "view"
+		} else if m.skipExpiredCursor >= remaining {
+			m.skipExpiredCursor = remaining - 1
+		}
+	}
+	return m, nil
+}
+
 func (m model) handleSkipListRemove(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "ctrl+c", "q":
@@ -596,7 +1027,7 @@ func (m model) handleSkipListRemove(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if len(m.skipChoices) > 0 && m.skipCursor < len(m.skipChoices) {
 			// Remove from skip list
 			channelToRemove := m.skipChoices[m.skipCursor]
-			delete(m.skipList, channelToRemove)
+			m.skipList.Remove(channelToRemove)
 			
 			// Remove from choices slice
 			m.skipChoices = append(m.skipChoices[:m.skipCursor], m.skipChoices[m.skipCursor+1:]...)
@@ -607,7 +1038,7 @@ func (m model) handleSkipListRemove(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 			
 			// Save to file
-			err := slack.SaveSkipList("config/skiplist.json", m.skipList)
+			err := slack.SaveSkipList(slack.DefaultSkipListPath, m.skipList)
 			if err != nil {
 				m.err = err
 			}
@@ -618,19 +1049,85 @@ func (m model) handleSkipListRemove(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+func (m model) handleJournalScreen(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "q":
+		m.state = MainMenu
+		return m, nil
+	case "up", "k":
+		if m.journalCursor > 0 {
+			m.journalCursor--
+		}
+		if m.journalCursor < m.journalOffset {
+			m.journalOffset = m.journalCursor
+		}
+	case "down", "j":
+		if m.journalCursor < len(m.journal)-1 {
+			m.journalCursor++
+		}
+		if m.journalCursor >= m.journalOffset+10 {
+			m.journalOffset = m.journalCursor - 9
+		}
+	case " ":
+		if _, ok := m.journalSelected[m.journalCursor]; ok {
+			delete(m.journalSelected, m.journalCursor)
+		} else {
+			m.journalSelected[m.journalCursor] = struct{}{}
+		}
+	case "enter":
+		if len(m.journalSelected) > 0 {
+			return m.rejoinSelectedChannels()
+		}
+	}
+	return m, nil
+}
+
+// loadJournal loads the channels left by the cleaner that can be rejoined
+func (m model) loadJournal() (tea.Model, tea.Cmd) {
+	entries, err := slack.LoadJournal(slack.DefaultJournalPath)
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+	
+	m.journal = slack.PendingJournalEntries(entries)
+	m.journalCursor = 0
+	m.journalOffset = 0
+	m.journalSelected = make(map[int]struct{})
+	m.rejoinResults = nil
+	m.state = JournalScreen
+	return m, nil
+}
+
+// rejoinSelectedChannels rejoins the selected journal entries
+func (m model) rejoinSelectedChannels() (tea.Model, tea.Cmd) {
+	m.state = LoadingScreen
+	m.loadingMsg = "Rejoining selected channels..."
+	
+	selectedEntries := make([]slack.JournalEntry, 0)
+	for i := range m.journalSelected {
+		if i < len(m.journal) {
+			selectedEntries = append(selectedEntries, m.journal[i])
+		}
+	}
+	
+	return m, func() tea.Msg {
+		token := config.GetWorkspaceToken()
+		cleaner := slack.NewCleanerFromConfig(token, m.config)
+		return channelsRejoinedMsg{cleaner.RejoinChannels(selectedEntries)}
+	}
+}
+
 // loadSkipList loads the skip list for editing
 func (m model) loadSkipList() (tea.Model, tea.Cmd) {
-	skipList, err := slack.LoadSkipList("config/skiplist.json")
+	skipList, err := slack.LoadSkipList(slack.DefaultSkipListPath)
 	if err != nil {
 		m.err = err
 		return m, nil
 	}
 	
 	m.skipList = skipList
-	m.skipChoices = make([]string, 0, len(skipList))
-	for name := range skipList {
-		m.skipChoices = append(m.skipChoices, name)
-	}
+	m.skipChoices = skipList.Patterns()
 	m.skipCursor = 0
 	m.skipListOffset = 0
 	m.skipListMode = "view"
@@ -641,39 +1138,175 @@ func (m model) loadSkipList() (tea.Model, tea.Cmd) {
 
 // loadChannels loads channels for leaving
 func (m model) loadChannels() (tea.Model, tea.Cmd) {
+	return m.startScan("Loading channels...")
+}
+
+func (m model) startChannelSearch() (tea.Model, tea.Cmd) {
+	return m.startScan("Searching for stale channels...")
+}
+
+// startScan runs a scan in the background. Progress and the final result arrive
+// through scanEvents, which waitForScanEvent reads one message at a time.
+func (m model) startScan(loadingMsg string) (tea.Model, tea.Cmd) {
 	m.state = LoadingScreen
-	m.loadingMsg = "Loading channels..."
-	
-	return m, func() tea.Msg {
+	m.loadingMsg = loadingMsg
+	m.err = nil
+	m.channels = nil
+	m.selected = make(map[int]struct{})
+	m.cursor = 0
+	m.resultsOffset = 0
+	m.scanStopped = false
+	
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan tea.Msg, 16)
+	m.scanCancel = cancel
+	m.scanEvents = events
+	m.scanProgress = slack.ScanProgress{}
+	m.scanStarted = time.Now()
+	appConfig := m.config
+	forceRescan := m.forceRescan
+	m.forceRescan = false // A full rescan is a one-off
+	
+	scan := func() tea.Msg {
 		token := config.GetWorkspaceToken()
-		cleaner := slack.NewCleaner(token, m.config.Limit, slack.GetChannelTypes(m.config.Types), m.config.Days, m.config.Keyword, m.config.Verbose)
-		channels, err := cleaner.GetFilteredChannels()
-		if err != nil {
-			return errorMsg{err}
+		cleaner := slack.NewCleanerFromConfig(token, appConfig)
+		cleaner.ForceRescan = forceRescan
+		cleaner.Progress = func(p slack.ScanProgress) {
+			select {
+			case events <- scanProgressMsg{p}:
+			default: // The TUI is behind; a later snapshot supersedes this one
+			}
+		}
+		cleaner.Found = func(ch slack.ChannelInfo) {
+			events <- channelFoundMsg{ch}
 		}
-		return channelsLoadedMsg{channels}
+		channels, err := cleaner.GetFilteredChannels(ctx)
+		switch {
+		case errors.Is(err, context.Canceled):
+			events <- scanCancelledMsg{}
+		case err != nil:
+			events <- errorMsg{err}
+		default:
+			events <- channelsLoadedMsg{channels, cleaner.Protected}
+		}
+		return nil
 	}
+	return m, tea.Batch(scan, waitForScanEvent(events), scanTick())
 }
 
-func (m model) startChannelSearch() (tea.Model, tea.Cmd) {
-	m.state = LoadingScreen
-	m.loadingMsg = "Searching for stale channels..."
+// handleScanEvent applies a progress update, a streamed result or the final outcome
+// of the running scan
+func (m model) handleScanEvent(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case scanProgressMsg:
+		m.scanProgress = msg.progress
+	case channelFoundMsg:
+		// Append so the indexes of selected rows stay valid while the scan runs
+		m.channels = append(m.channels, msg.channel)
+		if m.state == LoadingScreen {
+			m.state = ResultsScreen
+		}
+	case scanCancelledMsg:
+		m = m.finishScan()
+		if m.state == LoadingScreen {
+			m.state = FilterScreen
+		} else {
+			m.scanStopped = true
+		}
+		return m, nil
+	case errorMsg:
+		m = m.finishScan()
+		m.err = msg
+		return m, nil
+	case channelsLoadedMsg:
+		m = m.finishScan()
+		m.protected = msg.protected
+		if m.state == LoadingScreen {
+			m.state = ResultsScreen
+		}
+		m = m.replaceChannels(msg.channels)
+		return m, nil
+	}
+	return m, waitForScanEvent(m.scanEvents)
+}
+
+// replaceChannels swaps in the final, sorted scan results, keeping the selection
+// and cursor on the same channels
+func (m model) replaceChannels(channels []slack.ChannelInfo) model {
+	selectedIDs := make(map[string]bool)
+	for i := range m.selected {
+		if i < len(m.channels) {
+			selectedIDs[m.channels[i].ID] = true
+		}
+	}
+	cursorID := ""
+	if m.cursor < len(m.channels) {
+		cursorID = m.channels[m.cursor].ID
+	}
 	
-	return m, func() tea.Msg {
-		token := config.GetWorkspaceToken()
-		cleaner := slack.NewCleaner(token, m.config.Limit, slack.GetChannelTypes(m.config.Types), m.config.Days, m.config.Keyword, m.config.Verbose)
-		channels, err := cleaner.GetFilteredChannels()
-		if err != nil {
-			return errorMsg{err}
+	m.channels = channels
+	m.selected = make(map[int]struct{})
+	m.cursor = 0
+	for i, ch := range channels {
+		if selectedIDs[ch.ID] {
+			m.selected[i] = struct{}{}
+		}
+		if ch.ID == cursorID {
+			m.cursor = i
 		}
-		return channelsLoadedMsg{channels}
 	}
+	m.resultsOffset = m.cursor / 12 * 12
+	return m
 }
 
-// leaveSelectedChannels leaves the selected channels
+// finishScan releases the state of a scan that has ended
+func (m model) finishScan() model {
+	if m.scanCancel != nil {
+		m.scanCancel()
+	}
+	m.scanCancel = nil
+	m.scanEvents = nil
+	return m
+}
+
+// waitForScanEvent reads the next progress update or result of a running scan
+func waitForScanEvent(events chan tea.Msg) tea.Cmd {
+	if events == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		return scanEventMsg{events, <-events}
+	}
+}
+
+// isFinalScanMsg reports whether msg is the last message a scan sends
+func isFinalScanMsg(msg tea.Msg) bool {
+	switch msg.(type) {
+	case channelsLoadedMsg, scanCancelledMsg, errorMsg:
+		return true
+	}
+	return false
+}
+
+func scanTick() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
+		return scanTickMsg(t)
+	})
+}
+
+// leaveSelectedChannels leaves or archives the selected channels, as confirmed
 func (m model) leaveSelectedChannels() (tea.Model, tea.Cmd) {
 	m.state = LoadingScreen
 	m.loadingMsg = "Leaving selected channels..."
+	switch m.confirmAction {
+	case slack.ActionArchive:
+		m.loadingMsg = "Archiving selected channels..."
+	case slack.ActionNotice:
+		m.loadingMsg = "Posting pre-archive notices..."
+	case slack.ActionMute:
+		m.loadingMsg = "Muting selected channels..."
+	}
+	action := m.confirmAction
 	
 	selectedChannels := make([]slack.ChannelInfo, 0)
 	for i := range m.selected {
@@ -684,12 +1317,8 @@ func (m model) leaveSelectedChannels() (tea.Model, tea.Cmd) {
 	
 	return m, func() tea.Msg {
 		token := config.GetWorkspaceToken()
-		cleaner := slack.NewCleaner(token, m.config.Limit, slack.GetChannelTypes(m.config.Types), m.config.Days, m.config.Keyword, m.config.Verbose)
-		err := cleaner.LeaveChannels(selectedChannels)
-		if err != nil {
-			return errorMsg{err}
-		}
-		return channelsLeftMsg{}
+		cleaner := slack.NewCleanerFromConfig(token, m.config)
+		return channelsLeftMsg{cleaner.Apply(action, selectedChannels)}
 	}
 }
 
@@ -709,6 +1338,10 @@ func (m model) View() string {
 		return m.renderSkipListScreen()
 	case LoadingScreen:
 		return m.renderLoadingScreen()
+	case JournalScreen:
+		return m.renderJournalScreen()
+	case LeaveSummaryScreen:
+		return m.renderLeaveSummaryScreen()
 	}
 	return ""
 }
@@ -805,7 +1438,16 @@ func (m model) renderConfigView() string {
 	b.WriteString(fmt.Sprintf("Limit: %d\n", m.config.Limit))
 	b.WriteString(fmt.Sprintf("Types: %s\n", strings.Join(m.config.Types, ", ")))
 	b.WriteString(fmt.Sprintf("Verbose: %t\n", m.config.Verbose))
-	b.WriteString(fmt.Sprintf("Keyword: %s\n", m.config.Keyword))
+	b.WriteString(fmt.Sprintf("Include: %s\n", listSummary(m.config.Include, "all channels")))
+	b.WriteString(fmt.Sprintf("Exclude: %s\n", listSummary(m.config.Exclude, "none")))
+	b.WriteString(fmt.Sprintf("Include Empty: %t\n", m.config.IncludeEmpty))
+	b.WriteString(fmt.Sprintf("Include Threads: %t\n", m.config.IncludeThreads))
+	b.WriteString(fmt.Sprintf("Staleness: %s\n", m.config.Staleness))
+	b.WriteString(fmt.Sprintf("Export Before Leave: %t (to %s)\n", m.config.Export.Enabled, m.config.Export.Dir))
+	b.WriteString(fmt.Sprintf("Rules: %s\n", rulesSummary(m.config.Rules)))
+	if m.config.Policy != "" {
+		b.WriteString(fmt.Sprintf("Policy: %s\n", m.config.Policy))
+	}
 	
 	b.WriteString("\n")
 	b.WriteString(m.styles.subtitle.Render("Press 'e' to edit, Enter to return to main menu"))
@@ -824,7 +1466,12 @@ func (m model) renderConfigEdit() string {
 		fmt.Sprintf("Limit: %d", m.config.Limit),
 		fmt.Sprintf("Types: %s", strings.Join(m.config.Types, ",")),
 		fmt.Sprintf("Verbose: %t", m.config.Verbose),
-		fmt.Sprintf("Keyword: %s", m.config.Keyword),
+		fmt.Sprintf("Include: %s", listSummary(m.config.Include, "all channels")),
+		fmt.Sprintf("Exclude: %s", listSummary(m.config.Exclude, "none")),
+		fmt.Sprintf("Include Empty: %t", m.config.IncludeEmpty),
+		fmt.Sprintf("Include Threads: %t", m.config.IncludeThreads),
+		fmt.Sprintf("Staleness (channel/self): %s", m.config.Staleness),
+		fmt.Sprintf("Export Before Leave: %t", m.config.Export.Enabled),
 	}
 	
 	for i, field := range fields {
@@ -842,6 +1489,10 @@ func (m model) renderConfigEdit() string {
 }
 
 func (m model) renderConfigFieldEdit() string {
+	if m.editingField == "include" || m.editingField == "exclude" {
+		return m.renderConfigListEdit()
+	}
+	
 	var b strings.Builder
 	
 	b.WriteString(m.styles.title.Render(fmt.Sprintf("⚙️  Edit %s", strings.Title(m.editingField))))
@@ -864,6 +1515,63 @@ func (m model) renderConfigFieldEdit() string {
 	return m.getResponsiveBorder().Render(b.String())
 }
 
+// renderConfigListEdit shows the include or exclude list with the entry being added
+func (m model) renderConfigListEdit() string {
+	var b strings.Builder
+	
+	b.WriteString(m.styles.title.Render(fmt.Sprintf("⚙️  Edit %s List", strings.Title(m.editingField))))
+	b.WriteString("\n\n")
+	if m.editingField == "exclude" {
+		b.WriteString("Channels matching any of these are left out.\n")
+	} else {
+		b.WriteString("Only channels matching one of these are scanned; an empty list scans all.\n")
+	}
+	b.WriteString(m.styles.info.Render("Entries ignore case: a substring (standup), a glob (proj-*-archive) or a regex (re:^inc-\\d+$)"))
+	b.WriteString("\n\n")
+	
+	list := m.configList()
+	if len(list) == 0 {
+		b.WriteString(m.styles.subtitle.Render("  (no entries)"))
+		b.WriteString("\n")
+	}
+	for i, entry := range list {
+		cursor := " "
+		if m.listCursor == i && !m.listAdding {
+			cursor = m.styles.cursor.Render(">")
+		}
+		b.WriteString(fmt.Sprintf("%s %s\n", cursor, entry))
+	}
+	
+	if m.listAdding {
+		b.WriteString("\nNew entry: ")
+		b.WriteString(m.configInput)
+		b.WriteString(m.styles.cursor.Render("_"))
+		b.WriteString("\n")
+	}
+	if m.err != nil {
+		b.WriteString("\n")
+		b.WriteString(m.styles.error.Render(fmt.Sprintf("❌ %v", m.err)))
+		b.WriteString("\n")
+	}
+	
+	b.WriteString("\n")
+	if m.listAdding {
+		b.WriteString(m.styles.subtitle.Render("Type the entry and press Enter to add it, Esc to cancel"))
+	} else {
+		b.WriteString(m.styles.subtitle.Render("'a' to add, 'd' to delete the selected entry, q or Enter when done ('s' in the editor saves)"))
+	}
+	
+	return m.getResponsiveBorder().Render(b.String())
+}
+
+// listSummary joins list entries for display, or returns empty for an empty list
+func listSummary(list []string, empty string) string {
+	if len(list) == 0 {
+		return empty
+	}
+	return strings.Join(list, ", ")
+}
+
 func (m model) renderFilterScreen() string {
 	var b strings.Builder
 	
@@ -871,12 +1579,37 @@ func (m model) renderFilterScreen() string {
 	b.WriteString("\n\n")
 	
 	b.WriteString(fmt.Sprintf("Days: %d\n", m.config.Days))
-	b.WriteString(fmt.Sprintf("Keyword: %s\n", m.config.Keyword))
+	b.WriteString(fmt.Sprintf("Include: %s\n", listSummary(m.config.Include, "all channels")))
+	b.WriteString(fmt.Sprintf("Exclude: %s\n", listSummary(m.config.Exclude, "none")))
 	b.WriteString(fmt.Sprintf("Limit: %d\n", m.config.Limit))
 	b.WriteString(fmt.Sprintf("Types: %s\n", strings.Join(m.config.Types, ", ")))
+	b.WriteString(fmt.Sprintf("Include Empty: %t\n", m.config.IncludeEmpty))
+	b.WriteString(fmt.Sprintf("Include Threads: %t\n", m.config.IncludeThreads))
+	b.WriteString(fmt.Sprintf("Staleness: %s\n", m.config.Staleness))
+	if len(m.config.Rules) > 0 {
+		b.WriteString(fmt.Sprintf("Rules: %s\n", rulesSummary(m.config.Rules)))
+	}
+	if m.config.Cache.Enabled {
+		b.WriteString(fmt.Sprintf("Force Full Rescan: %t\n", m.forceRescan))
+	}
+	b.WriteString(m.renderPolicyLine())
 	
 	b.WriteString("\n")
-	b.WriteString(m.styles.subtitle.Render("Press Enter to start search"))
+	if m.editingPolicy {
+		b.WriteString(m.styles.info.Render("Fields: " + strings.Join(policy.Fields(), ", ")))
+		b.WriteString("\n")
+		b.WriteString(m.styles.info.Render(`Operators: == != < <= > >= =~ !~ ! && || ( ), e.g. days_idle > 45 && members < 5 && !is_shared`))
+		b.WriteString("\n\n")
+		b.WriteString(m.styles.subtitle.Render("Press Enter to apply the policy (empty for none), Esc to cancel"))
+		return m.getResponsiveBorder().Render(b.String())
+	}
+	if m.config.Cache.Enabled {
+		b.WriteString(m.styles.subtitle.Render("Press Enter to start search, 'r' to toggle a full rescan that ignores cached results"))
+	} else {
+		b.WriteString(m.styles.subtitle.Render("Press Enter to start search"))
+	}
+	b.WriteString("\n")
+	b.WriteString(m.styles.subtitle.Render("'p' to edit the policy deciding which channels are stale, instead of the days"))
 	
 	return m.getResponsiveBorder().Render(b.String())
 }
@@ -894,7 +1627,23 @@ func (m model) renderResultsScreen() string {
 		return m.getResponsiveBorder().Render(b.String())
 	}
 	
-	b.WriteString(fmt.Sprintf("Found %d channel(s):\n\n", len(m.channels)))
+	undetermined := 0
+	for _, ch := range m.channels {
+		if ch.Undetermined() {
+			undetermined++
+		}
+	}
+	if undetermined > 0 {
+		b.WriteString(fmt.Sprintf("Found %d channel(s), %d could not be determined and can't be selected:\n", len(m.channels), undetermined))
+	} else {
+		b.WriteString(fmt.Sprintf("Found %d channel(s):\n", len(m.channels)))
+	}
+	if muted := countMuted(m.channels); muted > 0 {
+		b.WriteString(m.styles.info.Render(fmt.Sprintf("🔇 %d muted and still stale; press M to select them for a final leave", muted)))
+		b.WriteString("\n")
+	}
+	b.WriteString(m.renderScanStatus())
+	b.WriteString("\n")
 	
 	// Show paginated results (12 items per page to ensure headers are visible)
 	start := m.resultsOffset
@@ -908,8 +1657,19 @@ func (m model) renderResultsScreen() string {
 	// Calculate column widths based on terminal width
 	availableWidth := m.width - 10 // Account for border and padding
 	selectColWidth := 8  // Fixed width for selection column
-	nameColWidth := (availableWidth - selectColWidth) * 3 / 5 // 60% for name
-	dateColWidth := (availableWidth - selectColWidth) * 2 / 5  // 40% for date
+	sourceColWidth := 16 // Fixed width for activity source column
+	ruleColWidth := 0    // Matched rules get a column only when rules are configured
+	if len(m.config.Rules) > 0 {
+		ruleColWidth = 22
+	}
+	nameColWidth := (availableWidth - selectColWidth - sourceColWidth - ruleColWidth) * 3 / 5 // 60% for name
+	dateColWidth := (availableWidth - selectColWidth - sourceColWidth - ruleColWidth) * 2 / 5  // 40% for date
+	selfActivity := m.config.Staleness == config.StalenessSelf
+	if selfActivity {
+		// Two date columns side by side: the channel's and the user's own activity
+		nameColWidth = (availableWidth - selectColWidth - sourceColWidth - ruleColWidth) * 2 / 5
+		dateColWidth = (availableWidth - selectColWidth - sourceColWidth - ruleColWidth) * 3 / 10
+	}
 	
 	if nameColWidth < 15 {
 		nameColWidth = 15
@@ -932,10 +1692,7 @@ func (m model) renderResultsScreen() string {
 			checked = m.styles.selected.Render("✓")
 		}
 		
-		lastSeen := "No messages"
-		if !ch.LastSeen.IsZero() {
-			lastSeen = ch.LastSeen.Format("2006-01-02 15:04:05")
-		}
+		lastSeen := lastSeenLabel(ch)
 		
 		// Truncate name if too long
 		name := ch.Name
@@ -943,20 +1700,45 @@ func (m model) renderResultsScreen() string {
 			name = name[:nameColWidth-6] + "..."
 		}
 		
-		rows = append(rows, []string{
+		if ch.Muted {
+			name += " 🔇"
+		}
+		
+		row := []string{
 			fmt.Sprintf("%s [%s]", cursor, checked),
 			fmt.Sprintf("#%s", name),
 			lastSeen,
-		})
+			activitySourceLabel(ch),
+		}
+		if selfActivity {
+			row = append(row, myLastSeenLabel(ch))
+		}
+		if ruleColWidth > 0 {
+			row = append(row, ruleLabel(ch))
+		}
+		rows = append(rows, row)
+	}
+	
+	headers := []string{"Select", "Channel", "Last Activity", "Source"}
+	if selfActivity {
+		headers = append(headers, "My Activity")
+	}
+	ruleCol := -1
+	if ruleColWidth > 0 {
+		ruleCol = len(headers)
+		headers = append(headers, "Rule")
 	}
 	
 	// Create table with better styling
 	t := table.New().
 		Border(lipgloss.RoundedBorder()).
 		BorderStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("#874BFD"))).
-		Headers("Select", "Channel", "Last Activity").
+		Headers(headers...).
 		Rows(rows...).
 		StyleFunc(func(row, col int) lipgloss.Style {
+			if col == ruleCol {
+				return lipgloss.NewStyle().Width(ruleColWidth)
+			}
 			switch col {
 			case 0:
 				return lipgloss.NewStyle().Width(selectColWidth).Align(lipgloss.Center)
@@ -964,6 +1746,10 @@ func (m model) renderResultsScreen() string {
 				return lipgloss.NewStyle().Width(nameColWidth)
 			case 2:
 				return lipgloss.NewStyle().Width(dateColWidth)
+			case 3:
+				return lipgloss.NewStyle().Width(sourceColWidth)
+			case 4:
+				return lipgloss.NewStyle().Width(dateColWidth)
 			default:
 				return lipgloss.NewStyle()
 			}
@@ -974,6 +1760,8 @@ func (m model) renderResultsScreen() string {
 	} else {
 		b.WriteString(t.Render())
 	}
+	b.WriteString("\n")
+	b.WriteString(m.renderChannelDetail())
 	
 	// Show pagination info
 	if len(m.channels) > 12 {
@@ -983,26 +1771,275 @@ func (m model) renderResultsScreen() string {
 	}
 	
 	b.WriteString("\n")
-	b.WriteString(m.styles.subtitle.Render("Use ↑↓ to navigate, Space to select, Enter to leave selected"))
+	if m.scanEvents != nil {
+		b.WriteString(m.styles.subtitle.Render("Use ↑↓ to navigate, Space to select, Esc to stop scanning"))
+	} else {
+		b.WriteString(m.styles.subtitle.Render("Use ↑↓ to navigate, Space to select, Enter to leave selected, 'm' to mute selected"))
+		if m.config.Staleness != config.StalenessSelf {
+			b.WriteString("\n")
+			b.WriteString(m.styles.subtitle.Render("'a' to archive selected for everyone, 'n' to post a pre-archive notice (workspace admins)"))
+		}
+	}
 	b.WriteString("\n")
 	b.WriteString(m.styles.subtitle.Render("Page Up/Down (b/f), Home/End (g/G), 't' to toggle view, q to quit"))
 	
 	return m.getResponsiveBorder().Render(b.String())
 }
 
+// renderChannelDetail shows everything known about the channel under the cursor
+func (m model) renderChannelDetail() string {
+	if m.cursor < 0 || m.cursor >= len(m.channels) {
+		return ""
+	}
+	ch := m.channels[m.cursor]
+	
+	// Long topics would push the pane past the terminal width
+	width := m.width - 20
+	if width < 30 {
+		width = 30
+	}
+	fit := func(s string) string {
+		s = strings.Join(strings.Fields(s), " ")
+		if runes := []rune(s); len(runes) > width {
+			s = string(runes[:width-3]) + "..."
+		}
+		return s
+	}
+	
+	var b strings.Builder
+	b.WriteString(m.styles.title.Render(fmt.Sprintf("#%s", ch.Name)))
+	b.WriteString(fmt.Sprintf(" %s\n", ch.ID))
+	
+	details := []string{ch.Type, fmt.Sprintf("%d member(s)", ch.NumMembers)}
+	if !ch.Created.IsZero() {
+		created := "created " + ch.Created.Format("2006-01-02")
+		if ch.Creator != "" {
+			created += " by " + ch.Creator
+		}
+		details = append(details, created)
+	}
+	if ch.IsGeneral {
+		details = append(details, "general")
+	}
+	if ch.IsExtShared {
+		details = append(details, "shared with another organization")
+	} else if ch.IsShared {
+		details = append(details, "shared")
+	}
+	b.WriteString(strings.Join(details, " · "))
+	b.WriteString("\n")
+	
+	activity := "Last activity: " + lastSeenLabel(ch)
+	if ch.Rule != nil {
+		activity += " · Rule: " + ch.Rule.String()
+	}
+	b.WriteString(activity + "\n")
+	if ch.Topic != "" {
+		b.WriteString("Topic: " + fit(ch.Topic) + "\n")
+	}
+	if ch.Purpose != "" {
+		b.WriteString("Purpose: " + fit(ch.Purpose) + "\n")
+	}
+	if ch.Undetermined() {
+		b.WriteString(m.styles.error.Render("Error: "+fit(ch.Error)) + "\n")
+	}
+	return lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color("#874BFD")).
+		Padding(0, 1).
+		Render(strings.TrimSuffix(b.String(), "\n")) + "\n"
+}
+
+// renderScanStatus describes a scan that is still running or ended early
+func (m model) renderScanStatus() string {
+	switch {
+	case m.err != nil:
+		return m.styles.error.Render(fmt.Sprintf("❌ Scan failed, results are partial: %v", m.err)) + "\n"
+	case m.scanStopped:
+		return m.styles.warning.Render("⚠️  Scan stopped early, results are partial") + "\n"
+	case m.scanEvents == nil:
+		return ""
+	}
+	
+	p := m.scanProgress
+	status := fmt.Sprintf("⏳ Still scanning %d channel(s)", p.Remaining())
+	if !p.Listed {
+		status += ", more being listed"
+	}
+	if wait := time.Until(p.WaitUntil); wait > 0 {
+		status += fmt.Sprintf(" (rate limited, resuming in %s)", wait.Round(time.Second))
+	}
+	return m.styles.info.Render(status) + "\n"
+}
+
+// myLastSeenLabel formats the user's own last activity in a channel
+func myLastSeenLabel(ch slack.ChannelInfo) string {
+	if ch.MyLastSeen.IsZero() {
+		return "None since cutoff"
+	}
+	return ch.MyLastSeen.Format("2006-01-02 15:04:05")
+}
+
+// lastSeenLabel formats a channel's last activity
+func lastSeenLabel(ch slack.ChannelInfo) string {
+	if ch.Undetermined() {
+		return "❓ Could not determine"
+	}
+	if ch.LastSeen.IsZero() {
+		return "No messages"
+	}
+	return ch.LastSeen.Format("2006-01-02 15:04:05")
+}
+
+// countMuted returns how many channels were muted earlier and are still stale
+func countMuted(channels []slack.ChannelInfo) int {
+	n := 0
+	for _, ch := range channels {
+		if ch.Muted && !ch.Undetermined() {
+			n++
+		}
+	}
+	return n
+}
+
+// renderPolicyLine shows the policy, or the one being entered, with a marker under
+// the column a parse error points at
+func (m model) renderPolicyLine() string {
+	const label = "Policy: "
+	expr := m.config.Policy
+	if m.editingPolicy {
+		expr = m.policyInput
+	}
+	
+	var b strings.Builder
+	switch {
+	case m.editingPolicy:
+		b.WriteString(label + expr + m.styles.cursor.Render("█") + "\n")
+	case expr == "":
+		b.WriteString(label + "none\n")
+	default:
+		b.WriteString(label + expr + "\n")
+	}
+	
+	if m.policyErr != nil {
+		var perr *policy.Error
+		if errors.As(m.policyErr, &perr) {
+			b.WriteString(m.styles.error.Render(strings.Repeat(" ", len(label)+perr.Col-1) + "^"))
+			b.WriteString("\n")
+		}
+		b.WriteString(m.styles.error.Render("❌ Invalid policy: " + m.policyErr.Error()))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// rulesSummary lists the configured rules in order, e.g. "inc-* 7d, proj-* 60d mute"
+func rulesSummary(rules []config.RuleConfig) string {
+	if len(rules) == 0 {
+		return "none (edit \"rules\" in config/app.json)"
+	}
+	parts := make([]string, 0, len(rules))
+	for _, r := range rules {
+		part := r.Name
+		if part == "" {
+			part = r.Pattern
+		}
+		if part == "" {
+			part = "*"
+		}
+		if r.Type != "" {
+			part += " " + r.Type
+		}
+		if r.Days > 0 {
+			part += fmt.Sprintf(" %dd", r.Days)
+		}
+		if r.Action != "" && r.Action != config.RuleLeave {
+			part += " " + r.Action
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ruleLabel names the rule that decided a channel's threshold, if any
+func ruleLabel(ch slack.ChannelInfo) string {
+	if ch.Rule == nil {
+		return "-"
+	}
+	return ch.Rule.String()
+}
+
+// isRuleMute reports whether leaving the channel mutes it instead, as its rule says
+func isRuleMute(ch slack.ChannelInfo) bool {
+	return ch.Rule != nil && ch.Rule.Action == slack.ActionMute
+}
+
+// countSelectedRuleMutes returns how many selected channels a leave would mute instead
+func (m model) countSelectedRuleMutes() int {
+	n := 0
+	for i := range m.selected {
+		if i < len(m.channels) && isRuleMute(m.channels[i]) {
+			n++
+		}
+	}
+	return n
+}
+
+// activitySourceLabel describes where a channel's last activity came from
+func activitySourceLabel(ch slack.ChannelInfo) string {
+	if ch.Undetermined() {
+		return "⚠️  error"
+	}
+	switch ch.ActivitySource {
+	case slack.SourceMessage:
+		return "💬 message"
+	case slack.SourceThreadReply:
+		return "🧵 thread reply"
+	}
+	return "-"
+}
+
 func (m model) renderConfirmationScreen() string {
+	if m.confirmAction == slack.ActionArchive {
+		return m.renderArchiveConfirmation()
+	}
+	
 	var b strings.Builder
 	
 	b.WriteString(m.styles.title.Render("⚠️  Confirmation"))
 	b.WriteString("\n\n")
 	
 	selectedCount := len(m.selected)
-	b.WriteString(fmt.Sprintf("Are you sure you want to leave %d channel(s)?\n\n", selectedCount))
+	switch m.confirmAction {
+	case slack.ActionNotice:
+		b.WriteString(fmt.Sprintf("Post the pre-archive notice to %d channel(s)?\n", selectedCount))
+		b.WriteString(m.styles.info.Render(fmt.Sprintf("They can be archived with 'notices --archive' once %d days pass without new activity.", m.config.Notice.GraceDays)))
+		b.WriteString("\n\n")
+	case slack.ActionMute:
+		b.WriteString(fmt.Sprintf("Mute %d channel(s)?\n", selectedCount))
+		b.WriteString(m.styles.info.Render("You stay a member; press M in a later scan to select the ones still stale for a final leave."))
+		b.WriteString("\n\n")
+	default:
+		b.WriteString(fmt.Sprintf("Are you sure you want to leave %d channel(s)?\n", selectedCount))
+		if muted := m.countSelectedRuleMutes(); muted > 0 {
+			b.WriteString(m.styles.info.Render(fmt.Sprintf("%d of them are muted instead, as their rule says.", muted)))
+			b.WriteString("\n")
+		}
+		if m.config.Export.Enabled {
+			b.WriteString(m.styles.info.Render(fmt.Sprintf("Each channel's history is exported to %s first; channels that fail to export are not left.", m.config.Export.Dir)))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
 	
 	selectedChannels := make([]string, 0)
 	for i := range m.selected {
 		if i < len(m.channels) {
-			selectedChannels = append(selectedChannels, "#"+m.channels[i].Name)
+			name := "#" + m.channels[i].Name
+			if m.confirmAction == slack.ActionLeave && isRuleMute(m.channels[i]) {
+				name += " (mute)"
+			}
+			selectedChannels = append(selectedChannels, name)
 		}
 	}
 	
@@ -1011,13 +2048,106 @@ func (m model) renderConfirmationScreen() string {
 	}
 	
 	b.WriteString("\n")
-	b.WriteString(m.styles.warning.Render("This action cannot be undone!"))
-	b.WriteString("\n\n")
+	if m.confirmAction != slack.ActionMute {
+		b.WriteString(m.styles.warning.Render("This action cannot be undone!"))
+		b.WriteString("\n\n")
+	}
 	b.WriteString(m.styles.subtitle.Render("Press y to confirm, n to cancel"))
 	
 	return m.styles.border.Render(b.String())
 }
 
+// renderArchiveConfirmation asks for a typed confirmation, since archiving removes
+// the channel for every member and not just the current user
+func (m model) renderArchiveConfirmation() string {
+	var b strings.Builder
+	
+	b.WriteString(m.styles.title.Render("🗄️  Archive Channels"))
+	b.WriteString("\n\n")
+	
+	b.WriteString(fmt.Sprintf("Archive %d channel(s) for the whole workspace?\n\n", len(m.selected)))
+	
+	for i := range m.selected {
+		if i >= len(m.channels) {
+			continue
+		}
+		ch := m.channels[i]
+		if ch.IsGeneral {
+			b.WriteString(m.styles.warning.Render(fmt.Sprintf("  • #%s (general channel, can't be archived; will be skipped)", ch.Name)))
+			b.WriteString("\n")
+			continue
+		}
+		b.WriteString(fmt.Sprintf("  • #%s\n", ch.Name))
+	}
+	
+	b.WriteString("\n")
+	b.WriteString(m.styles.warning.Render("Every member loses the channel until an admin unarchives it. This needs workspace admin rights."))
+	b.WriteString("\n\n")
+	b.WriteString(fmt.Sprintf("Type 'archive' to confirm: %s_\n\n", m.confirmInput))
+	b.WriteString(m.styles.subtitle.Render("Press Enter to confirm, Esc to cancel"))
+	
+	return m.styles.border.Render(b.String())
+}
+
+func (m model) renderLeaveSummaryScreen() string {
+	var b strings.Builder
+	
+	report := m.leaveReport
+	if report == nil {
+		report = &slack.LeaveReport{Action: slack.ActionLeave}
+	}
+	
+	title := "📊 Leave Summary"
+	switch report.Action {
+	case slack.ActionArchive:
+		title = "📊 Archive Summary"
+	case slack.ActionNotice:
+		title = "📊 Notice Summary"
+	case slack.ActionMute:
+		title = "📊 Mute Summary"
+	}
+	b.WriteString(m.styles.title.Render(title))
+	b.WriteString("\n\n")
+	
+	b.WriteString(fmt.Sprintf("Processed %d channel(s): ", report.Total()))
+	b.WriteString(m.styles.success.Render(fmt.Sprintf("%d %s", len(report.Succeeded), report.Action.Done())))
+	b.WriteString(", ")
+	b.WriteString(m.styles.error.Render(fmt.Sprintf("%d failed", len(report.Failed))))
+	b.WriteString(", ")
+	b.WriteString(m.styles.warning.Render(fmt.Sprintf("%d skipped", len(report.Skipped))))
+	if len(report.Muted) > 0 {
+		b.WriteString(", ")
+		b.WriteString(m.styles.success.Render(fmt.Sprintf("%d muted by rule", len(report.Muted))))
+	}
+	b.WriteString("\n\n")
+	
+	for _, ch := range report.Succeeded {
+		b.WriteString(fmt.Sprintf("  ✅ #%s\n", ch.Name))
+	}
+	for _, ch := range report.Muted {
+		b.WriteString(fmt.Sprintf("  🔇 #%s (rule %s)\n", ch.Name, ch.Rule.Name))
+	}
+	for _, f := range report.Failed {
+		b.WriteString(m.styles.error.Render(fmt.Sprintf("  ❌ #%s: %v", f.Channel.Name, f.Err)))
+		b.WriteString("\n")
+	}
+	for _, sk := range report.Skipped {
+		b.WriteString(m.styles.warning.Render(fmt.Sprintf("  ⏭️  #%s: %s", sk.Channel.Name, sk.Reason)))
+		b.WriteString("\n")
+	}
+	
+	if report.JournalErr != nil {
+		b.WriteString("\n")
+		b.WriteString(m.styles.error.Render("⚠️  " + report.JournalErr.Error()))
+		b.WriteString("\n")
+	}
+	
+	b.WriteString("\n")
+	b.WriteString(m.styles.subtitle.Render("Press Enter or q to return to main menu"))
+	
+	return m.getResponsiveBorder().Render(b.String())
+}
+
 func (m model) renderSkipListScreen() string {
 	var b strings.Builder
 	
@@ -1028,6 +2158,8 @@ func (m model) renderSkipListScreen() string {
 		return m.renderSkipListAdd()
 	case "remove":
 		return m.renderSkipListRemove()
+	case "expired":
+		return m.renderSkipListExpired()
 	}
 	return m.getResponsiveBorder().Render(b.String())
 }
@@ -1059,7 +2191,21 @@ func (m model) renderSkipListView() string {
 		if m.skipCursor == start+i {
 			cursor = m.styles.cursor.Render(">")
 		}
-		b.WriteString(fmt.Sprintf("%s %s\n", cursor, name))
+		b.WriteString(fmt.Sprintf("%s %s", cursor, name))
+		if entry, ok := m.skipList.Entry(name); ok {
+			if entry.Reason != "" {
+				b.WriteString(m.styles.subtitle.Render("  — " + entry.Reason))
+			}
+			if entry.Expired(time.Now()) {
+				b.WriteString(m.styles.warning.Render("  ⌛ expired"))
+			} else if entry.ExpiresAt != nil {
+				b.WriteString(m.styles.subtitle.Render("  (until " + entry.ExpiresAt.Local().Format("2006-01-02") + ")"))
+			}
+		}
+		if protected := m.protectedBy(name); len(protected) > 0 {
+			b.WriteString(m.styles.info.Render("  🛡️  " + strings.Join(protected, ", ")))
+		}
+		b.WriteString("\n")
 	}
 	
 	// Show pagination info
@@ -1069,23 +2215,105 @@ func (m model) renderSkipListView() string {
 		b.WriteString(fmt.Sprintf("\n%s\n", m.styles.info.Render(fmt.Sprintf("Page %d/%d", currentPage, totalPages))))
 	}
 	
+	if len(m.protected) > 0 {
+		b.WriteString("\n")
+		b.WriteString(m.styles.subtitle.Render(fmt.Sprintf("🛡️  %d channel(s) were protected during the last scan", len(m.protected))))
+		b.WriteString("\n")
+	}
+	
+	if expired := len(m.skipList.Expired(time.Now())); expired > 0 {
+		b.WriteString("\n")
+		b.WriteString(m.styles.warning.Render(fmt.Sprintf("⌛ %d protection(s) expired and no longer apply. Press 'x' to review them.", expired)))
+		b.WriteString("\n")
+	}
+	
 	b.WriteString("\n")
-	b.WriteString(m.styles.subtitle.Render("Use ↑↓ to navigate, 'a' to add, 'd' to delete, Enter to return"))
+	b.WriteString(m.styles.subtitle.Render("Use ↑↓ to navigate, 'a' to add, 'd' to delete, 'x' to review expired, Enter to return"))
 	
 	return m.getResponsiveBorder().Render(b.String())
 }
 
+// protectedBy returns the channels a skip list pattern protected during the last scan
+func (m model) protectedBy(pattern string) []string {
+	var names []string
+	for _, p := range m.protected {
+		if p.Pattern == pattern {
+			names = append(names, "#"+p.Name)
+		}
+	}
+	return names
+}
+
 func (m model) renderSkipListAdd() string {
 	var b strings.Builder
 	
 	b.WriteString(m.styles.title.Render("➕ Add Channel to Skip List"))
 	b.WriteString("\n\n")
 	
-	b.WriteString("Enter channel name (without #):\n")
+	switch m.skipAddStep {
+	case 0:
+		b.WriteString("Enter channel name (without #), glob (team-*), regex (re:^inc-\\d+$) or ID (id:C0123456):\n")
+	case 1:
+		b.WriteString(fmt.Sprintf("Pattern: %s\n\n", m.skipAddEntry.Pattern))
+		b.WriteString("Why is this channel protected? (optional):\n")
+	case 2:
+		b.WriteString(fmt.Sprintf("Pattern: %s\n", m.skipAddEntry.Pattern))
+		b.WriteString(fmt.Sprintf("Reason: %s\n\n", m.skipAddEntry.Reason))
+		b.WriteString("Protect for how many days? (blank for no expiry):\n")
+	}
 	b.WriteString(fmt.Sprintf("> %s", m.skipListInput))
 	
+	if m.err != nil {
+		b.WriteString("\n\n")
+		b.WriteString(m.styles.error.Render("Error: " + m.err.Error()))
+	}
+	
 	b.WriteString("\n\n")
-	b.WriteString(m.styles.subtitle.Render("Type channel name and press Enter to add, q to cancel"))
+	if m.skipAddStep == 0 {
+		b.WriteString(m.styles.subtitle.Render("Type channel name and press Enter to continue, q to cancel"))
+	} else {
+		b.WriteString(m.styles.subtitle.Render("Type a value and press Enter to continue, Esc to cancel"))
+	}
+	
+	return m.getResponsiveBorder().Render(b.String())
+}
+
+func (m model) renderSkipListExpired() string {
+	var b strings.Builder
+	
+	b.WriteString(m.styles.title.Render("⌛ Review Expired Protections"))
+	b.WriteString("\n\n")
+	
+	b.WriteString(m.styles.info.Render("These entries no longer protect any channel."))
+	b.WriteString("\n\n")
+	
+	for i, e := range m.skipList.Expired(time.Now()) {
+		cursor := " "
+		if m.skipExpiredCursor == i {
+			cursor = m.styles.cursor.Render(">")
+		}
+		b.WriteString(fmt.Sprintf("%s %s", cursor, e.Pattern))
+		b.WriteString(m.styles.warning.Render(fmt.Sprintf("  expired %s", e.ExpiresAt.Local().Format("2006-01-02"))))
+		b.WriteString("\n")
+		
+		details := make([]string, 0, 3)
+		if e.Reason != "" {
+			details = append(details, "reason: "+e.Reason)
+		}
+		if e.AddedBy != "" {
+			details = append(details, "added by "+e.AddedBy)
+		}
+		if e.AddedAt != nil {
+			details = append(details, "on "+e.AddedAt.Local().Format("2006-01-02"))
+		}
+		if len(details) > 0 {
+			b.WriteString(m.styles.subtitle.Render("    " + strings.Join(details, ", ")))
+			b.WriteString("\n")
+		}
+	}
+	
+	b.WriteString("\n")
+	b.WriteString(m.styles.subtitle.Render("Use ↑↓ to navigate, 'r' to renew for 30 days, 'd' to delete, q to return"))
 	
 	return m.getResponsiveBorder().Render(b.String())
 }
@@ -1135,6 +2363,75 @@ func (m model) renderSkipListRemove() string {
 	return m.getResponsiveBorder().Render(b.String())
 }
 
+func (m model) renderJournalScreen() string {
+	var b strings.Builder
+	
+	b.WriteString(m.styles.title.Render("↩️  Rejoin Left Channels"))
+	b.WriteString("\n\n")
+	
+	if len(m.rejoinResults) > 0 {
+		for _, r := range m.rejoinResults {
+			switch {
+			case r.Err == nil:
+				b.WriteString(m.styles.success.Render(fmt.Sprintf("✅ Rejoined #%s", r.Entry.Name)))
+			case errors.Is(r.Err, slack.ErrPrivateRejoin):
+				b.WriteString(m.styles.warning.Render(fmt.Sprintf("🔒 #%s: %v", r.Entry.Name, r.Err)))
+			default:
+				b.WriteString(m.styles.error.Render(fmt.Sprintf("❌ #%s: %v", r.Entry.Name, r.Err)))
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+	
+	if len(m.journal) == 0 {
+		b.WriteString(m.styles.info.Render("No channels left by the cleaner to rejoin."))
+		b.WriteString("\n\n")
+		b.WriteString(m.styles.subtitle.Render("Press q to return to main menu"))
+		return m.getResponsiveBorder().Render(b.String())
+	}
+	
+	// Show paginated results (10 items per page)
+	start := m.journalOffset
+	end := start + 10
+	if end > len(m.journal) {
+		end = len(m.journal)
+	}
+	
+	for i, e := range m.journal[start:end] {
+		globalIndex := start + i
+		cursor := " "
+		if m.journalCursor == globalIndex {
+			cursor = m.styles.cursor.Render(">")
+		}
+		
+		checked := " "
+		if _, ok := m.journalSelected[globalIndex]; ok {
+			checked = m.styles.selected.Render("✓")
+		}
+		
+		note := ""
+		if e.Type == "private" {
+			note = m.styles.warning.Render("  🔒 private: invite only")
+		}
+		b.WriteString(fmt.Sprintf("%s [%s] #%s  left %s%s\n", cursor, checked, e.Name, e.LeftAt.Local().Format("2006-01-02 15:04"), note))
+	}
+	
+	// Show pagination info
+	if len(m.journal) > 10 {
+		currentPage := (m.journalOffset / 10) + 1
+		totalPages := (len(m.journal) + 9) / 10
+		b.WriteString(fmt.Sprintf("\n%s\n", m.styles.info.Render(fmt.Sprintf("Page %d/%d", currentPage, totalPages))))
+	}
+	
+	b.WriteString("\n")
+	b.WriteString(m.styles.subtitle.Render("Private channels can only be rejoined when a member invites you back."))
+	b.WriteString("\n")
+	b.WriteString(m.styles.subtitle.Render("Use ↑↓ to navigate, Space to select, Enter to rejoin selected, q to return"))
+	
+	return m.getResponsiveBorder().Render(b.String())
+}
+
 func (m model) renderLoadingScreen() string {
 	var b strings.Builder
 	
@@ -1143,11 +2440,82 @@ func (m model) renderLoadingScreen() string {
 	
 	b.WriteString(m.styles.info.Render(m.loadingMsg))
 	b.WriteString("\n")
-	b.WriteString(m.styles.subtitle.Render("Please wait..."))
+	
+	if m.err != nil {
+		b.WriteString("\n")
+		b.WriteString(m.styles.error.Render(fmt.Sprintf("❌ %v", m.err)))
+		b.WriteString("\n\n")
+		b.WriteString(m.styles.subtitle.Render("Press Enter to return to main menu"))
+		return m.getResponsiveBorder().Render(b.String())
+	}
+	
+	if m.scanEvents == nil {
+		b.WriteString(m.styles.subtitle.Render("Please wait..."))
+		return m.getResponsiveBorder().Render(b.String())
+	}
+	
+	b.WriteString("\n")
+	b.WriteString(m.renderScanProgress())
+	b.WriteString("\n")
+	b.WriteString(m.styles.subtitle.Render("Press Esc to cancel"))
 	
 	return m.getResponsiveBorder().Render(b.String())
 }
 
+// renderScanProgress shows a progress bar, counters and ETA for the running scan
+func (m model) renderScanProgress() string {
+	var b strings.Builder
+	p := m.scanProgress
+	
+	barWidth := m.width - 30
+	if barWidth > 50 {
+		barWidth = 50
+	}
+	if barWidth < 10 {
+		barWidth = 10
+	}
+	filled := 0
+	if p.Total > 0 {
+		filled = barWidth * p.Checked / p.Total
+	}
+	b.WriteString(m.styles.success.Render(strings.Repeat("█", filled)))
+	b.WriteString(strings.Repeat("░", barWidth-filled))
+	b.WriteString(fmt.Sprintf(" %d/%d channels", p.Checked, p.Total))
+	if !p.Listed {
+		b.WriteString(" (still listing)")
+	}
+	b.WriteString("\n\n")
+	
+	b.WriteString(fmt.Sprintf("Pages fetched: %d\n", p.Pages))
+	b.WriteString(fmt.Sprintf("Stale so far: %d\n", p.Matched))
+	if p.Errors > 0 {
+		b.WriteString(m.styles.warning.Render(fmt.Sprintf("Could not determine: %d", p.Errors)))
+		b.WriteString("\n")
+	}
+	if p.Cached > 0 {
+		b.WriteString(fmt.Sprintf("From cache: %d\n", p.Cached))
+	}
+	
+	elapsed := time.Since(m.scanStarted)
+	b.WriteString(fmt.Sprintf("Elapsed: %s", elapsed.Round(time.Second)))
+	if p.Checked > 0 && p.Remaining() > 0 {
+		eta := elapsed / time.Duration(p.Checked) * time.Duration(p.Remaining())
+		prefix := ""
+		if !p.Listed {
+			prefix = "at least "
+		}
+		b.WriteString(fmt.Sprintf(", ETA: %s%s", prefix, eta.Round(time.Second)))
+	}
+	b.WriteString("\n")
+	
+	if wait := time.Until(p.WaitUntil); wait > 0 {
+		b.WriteString(m.styles.warning.Render(fmt.Sprintf("⏳ Waiting on rate limit: %s", wait.Round(time.Second))))
+		b.WriteString("\n")
+	}
+	
+	return b.String()
+}
+
 // Message types for communication between components
 type errorMsg struct {
 	err error
@@ -1158,10 +2526,35 @@ func (e errorMsg) Error() string {
 }
 
 type channelsLoadedMsg struct {
-	channels []slack.ChannelInfo
+	channels  []slack.ChannelInfo
+	protected []slack.ProtectedChannel
+}
+
+// scanEventMsg carries a message from the scan that owns events
+type scanEventMsg struct {
+	events chan tea.Msg
+	msg    tea.Msg
+}
+
+type scanProgressMsg struct {
+	progress slack.ScanProgress
+}
+
+type channelFoundMsg struct {
+	channel slack.ChannelInfo
 }
 
-type channelsLeftMsg struct {}
+type scanTickMsg time.Time
+
+type scanCancelledMsg struct{}
+
+type channelsLeftMsg struct {
+	report *slack.LeaveReport
+}
+
+type channelsRejoinedMsg struct {
+	results []slack.RejoinResult
+}
 
 func (m model) renderSimpleListView(visibleChannels []slack.ChannelInfo, start int) string {
 	var b strings.Builder
@@ -1199,10 +2592,7 @@ func (m model) renderSimpleListView(visibleChannels []slack.ChannelInfo, start i
 			checked = m.styles.selected.Render("✓")
 		}
 		
-		lastSeen := "No messages"
-		if !ch.LastSeen.IsZero() {
-			lastSeen = ch.LastSeen.Format("2006-01-02 15:04:05")
-		}
+		lastSeen := lastSeenLabel(ch)
 		
 		// Truncate name if too long
 		name := ch.Name
@@ -1217,6 +2607,22 @@ func (m model) renderSimpleListView(visibleChannels []slack.ChannelInfo, start i
 		b.WriteString(fmt.Sprintf("#%-*s", maxNameWidth, name))
 		b.WriteString(" │ ")
 		b.WriteString(lastSeen)
+		if ch.ActivitySource == slack.SourceThreadReply {
+			b.WriteString(" 🧵")
+		}
+		if ch.Muted {
+			b.WriteString(" 🔇 muted")
+		}
+		if ch.Rule != nil {
+			b.WriteString(" 📏 " + ch.Rule.String())
+		}
+		if ch.Undetermined() {
+			b.WriteString(fmt.Sprintf(" (%s)", ch.Error))
+		}
+		if m.config.Staleness == config.StalenessSelf {
+			b.WriteString(" │ me: ")
+			b.WriteString(myLastSeenLabel(ch))
+		}
 		b.WriteString(" │\n")
 	}
 	
@@ -0,0 +1,73 @@
+// Package plan stores a reviewed list of channels to leave or archive so it can be applied later.
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"workspace-channels-cleaner/config"
+	"workspace-channels-cleaner/slack"
+)
+
+// Version is the current plan file format version
+const Version = 1
+
+// Plan freezes the result of a scan together with the settings that produced it
+type Plan struct {
+	Version   int                 `json:"version"`
+	CreatedAt time.Time           `json:"created_at"`
+	Cutoff    time.Time           `json:"cutoff"`
+	Config    config.AppConfig    `json:"config"`
+	Action    slack.Action        `json:"action"`
+	Channels  []slack.ChannelInfo `json:"channels"`
+}
+
+// New creates a plan for the given scan results
+func New(appConfig *config.AppConfig, cutoff time.Time, action slack.Action, channels []slack.ChannelInfo) *Plan {
+	if channels == nil {
+		channels = []slack.ChannelInfo{}
+	}
+	return &Plan{
+		Version:   Version,
+		CreatedAt: time.Now().UTC(),
+		Cutoff:    cutoff.UTC(),
+		Config:    *appConfig,
+		Action:    action,
+		Channels:  channels,
+	}
+}
+
+// Load reads a plan file
+func Load(path string) (*Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan: %w", err)
+	}
+
+	// Settings added after the plan was written keep their default value
+	p := Plan{Config: *config.DefaultConfig()}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse plan: %w", err)
+	}
+	if p.Version != Version {
+		return nil, fmt.Errorf("unsupported plan version %d (expected %d)", p.Version, Version)
+	}
+	// Plans written before archive mode only ever left channels
+	if p.Action, err = slack.ParseAction(string(p.Action)); err != nil {
+		return nil, fmt.Errorf("invalid plan: %w", err)
+	}
+	p.Config.MigrateKeyword()
+	return &p, nil
+}
+
+// Save writes a plan file
+func Save(path string, p *Plan) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal plan: %w", err)
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,64 @@
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"workspace-channels-cleaner/config"
+	"workspace-channels-cleaner/slack"
+)
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	appConfig := config.DefaultConfig()
+	appConfig.Days = 60
+	appConfig.Include = []string{"proj-*"}
+	cutoff := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	channels := []slack.ChannelInfo{{
+		ID:       "C1",
+		Name:     "proj-old",
+		Type:     "public",
+		LastSeen: time.Date(2023, 12, 24, 10, 0, 0, 0, time.UTC),
+		Created:  time.Date(2022, 1, 5, 0, 0, 0, 0, time.UTC),
+		Rule:     &slack.RuleMatch{Name: "projects", Days: 60, Action: slack.ActionLeave},
+	}}
+	want := New(appConfig, cutoff, slack.ActionArchive, channels)
+
+	path := filepath.Join(t.TempDir(), "plan.json")
+	if err := Save(path, want); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("loaded plan\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestLoadRejectsOtherVersions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	if err := os.WriteFile(path, []byte(`{"version": 99, "channels": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("a plan from an unknown version was loaded")
+	}
+}
+
+func TestLoadDefaultsToLeave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	if err := os.WriteFile(path, []byte(`{"version": 1, "channels": [{"id": "C1", "name": "old"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if p.Action != slack.ActionLeave || len(p.Channels) != 1 {
+		t.Errorf("loaded %+v, want a leave plan with one channel", p)
+	}
+}
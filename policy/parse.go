@@ -0,0 +1,414 @@
+package policy
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// tokenKind identifies a lexical token
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokOp
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string // The operator or identifier, or the unquoted string
+	pos  int    // Byte offset in the source
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of expression"
+	case tokString:
+		return strconv.Quote(t.text)
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// operators, longest first so "<=" isn't read as "<"
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "=~", "!~", "<", ">", "!"}
+
+type lexer struct {
+	src string
+	pos int
+}
+
+// next returns the next token
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.src) && (l.src[l.pos] == ' ' || l.src[l.pos] == '\t') {
+		l.pos++
+	}
+	start := l.pos
+	if l.pos >= len(l.src) {
+		return token{kind: tokEOF, pos: start}, nil
+	}
+
+	c, size := utf8.DecodeRuneInString(l.src[l.pos:])
+	switch {
+	case c == '(':
+		l.pos++
+		return token{kind: tokLParen, text: "(", pos: start}, nil
+	case c == ')':
+		l.pos++
+		return token{kind: tokRParen, text: ")", pos: start}, nil
+	case c == '"' || c == '\'':
+		return l.string(c)
+	case c >= '0' && c <= '9':
+		for l.pos < len(l.src) && (l.src[l.pos] >= '0' && l.src[l.pos] <= '9' || l.src[l.pos] == '.') {
+			l.pos++
+		}
+		return token{kind: tokNumber, text: l.src[start:l.pos], pos: start}, nil
+	case c == '_' || unicode.IsLetter(c):
+		for l.pos < len(l.src) {
+			r, n := utf8.DecodeRuneInString(l.src[l.pos:])
+			if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+				break
+			}
+			l.pos += n
+		}
+		return token{kind: tokIdent, text: l.src[start:l.pos], pos: start}, nil
+	}
+	for _, op := range operators {
+		if strings.HasPrefix(l.src[l.pos:], op) {
+			l.pos += len(op)
+			return token{kind: tokOp, text: op, pos: start}, nil
+		}
+	}
+	msg := fmt.Sprintf("unexpected character %q", l.src[start:start+size])
+	switch c {
+	case '=':
+		msg += ", use == to compare"
+	case '&', '|':
+		msg += fmt.Sprintf(", use %c%c", c, c)
+	}
+	return token{}, &Error{Src: l.src, Col: column(l.src, start), Msg: msg}
+}
+
+// string reads a string quoted with quote; a backslash escapes the next character
+func (l *lexer) string(quote rune) (token, error) {
+	start := l.pos
+	l.pos++
+	var b strings.Builder
+	for l.pos < len(l.src) {
+		c, size := utf8.DecodeRuneInString(l.src[l.pos:])
+		l.pos += size
+		switch {
+		case c == quote:
+			return token{kind: tokString, text: b.String(), pos: start}, nil
+		case c == '\\' && l.pos < len(l.src):
+			escaped, n := utf8.DecodeRuneInString(l.src[l.pos:])
+			l.pos += n
+			b.WriteRune(escaped)
+		default:
+			b.WriteRune(c)
+		}
+	}
+	return token{}, &Error{Src: l.src, Col: column(l.src, start), Msg: "string is never closed"}
+}
+
+// column converts a byte offset into a 1-based character column
+func column(src string, pos int) int {
+	return utf8.RuneCountInString(src[:pos]) + 1
+}
+
+// parser is a recursive descent parser over the grammar
+//
+//	or      = and { "||" and }
+//	and     = unary { "&&" unary }
+//	unary   = "!" unary | compare
+//	compare = operand [ op operand ]
+//	operand = field | number | string | "true" | "false" | "(" or ")"
+type parser struct {
+	lex  lexer
+	tok  token
+	err  error           // A lexer error, reported when the parser reaches it
+	used map[string]bool // Fields the expression refers to
+}
+
+func (p *parser) next() {
+	if p.err != nil {
+		return
+	}
+	tok, err := p.lex.next()
+	if err != nil {
+		p.err = err
+		tok = token{kind: tokEOF, pos: p.lex.pos}
+	}
+	p.tok = tok
+}
+
+func (p *parser) errorf(pos int, format string, args ...any) error {
+	if p.err != nil {
+		return p.err
+	}
+	return &Error{Src: p.lex.src, Col: column(p.lex.src, pos), Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) parseOr() (node, error) {
+	return p.parseLogical("||", p.parseAnd)
+}
+
+func (p *parser) parseAnd() (node, error) {
+	return p.parseLogical("&&", p.parseUnary)
+}
+
+// parseLogical parses operands joined by op, which must all be conditions
+func (p *parser) parseLogical(op string, operand func() (node, error)) (node, error) {
+	pos := p.tok.pos
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokOp && p.tok.text == op {
+		if left.typ() != Bool {
+			return nil, p.errorf(pos, "%s needs conditions on both sides, not a %s", op, left.typ())
+		}
+		p.next()
+		pos = p.tok.pos
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		if right.typ() != Bool {
+			return nil, p.errorf(pos, "%s needs conditions on both sides, not a %s", op, right.typ())
+		}
+		left = &logicalNode{and: op == "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.tok.kind == tokOp && p.tok.text == "!" {
+		p.next()
+		pos := p.tok.pos
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if operand.typ() != Bool {
+			return nil, p.errorf(pos, "! needs a condition, not a %s", operand.typ())
+		}
+		return &notNode{operand: operand}, nil
+	}
+	return p.parseCompare()
+}
+
+func (p *parser) parseCompare() (node, error) {
+	leftPos := p.tok.pos
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokOp || p.tok.text == "&&" || p.tok.text == "||" || p.tok.text == "!" {
+		return left, nil
+	}
+	op := p.tok
+	p.next()
+	rightPos := p.tok.pos
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	if op.text == "=~" || op.text == "!~" {
+		if left.typ() != String {
+			return nil, p.errorf(leftPos, "%s matches strings, not a %s", op.text, left.typ())
+		}
+		lit, ok := right.(*literalNode)
+		if !ok || lit.t != String {
+			return nil, p.errorf(rightPos, "%s needs a quoted regular expression on its right", op.text)
+		}
+		re, err := regexp.Compile(lit.v.s)
+		if err != nil {
+			return nil, p.errorf(rightPos, "invalid regular expression: %v", err)
+		}
+		return &matchNode{negate: op.text == "!~", operand: left, re: re}, nil
+	}
+
+	// A string next to a date is a date
+	if left.typ() == Date {
+		if right, err = p.asDate(right, rightPos); err != nil {
+			return nil, err
+		}
+	} else if right.typ() == Date {
+		if left, err = p.asDate(left, leftPos); err != nil {
+			return nil, err
+		}
+	}
+	if left.typ() != right.typ() {
+		return nil, p.errorf(op.pos, "can't compare a %s with a %s", left.typ(), right.typ())
+	}
+	if left.typ() == Bool && op.text != "==" && op.text != "!=" {
+		return nil, p.errorf(op.pos, "%s doesn't apply to conditions", op.text)
+	}
+	return &compareNode{op: op.text, left: left, right: right}, nil
+}
+
+// asDate converts a string literal such as "2024-01-31" into a date
+func (p *parser) asDate(n node, pos int) (node, error) {
+	lit, ok := n.(*literalNode)
+	if !ok || lit.t != String {
+		return n, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", lit.v.s, time.Local)
+	if err != nil {
+		return nil, p.errorf(pos, "invalid date %q, want YYYY-MM-DD", lit.v.s)
+	}
+	return &literalNode{t: Date, v: value{t: t}}, nil
+}
+
+func (p *parser) parseOperand() (node, error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokLParen:
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokRParen {
+			return nil, p.errorf(p.tok.pos, "expected ) but found %s", p.tok)
+		}
+		p.next()
+		return inner, nil
+	case tokNumber:
+		n, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, p.errorf(tok.pos, "invalid number %s", tok.text)
+		}
+		p.next()
+		return &literalNode{t: Number, v: value{n: n}}, nil
+	case tokString:
+		p.next()
+		return &literalNode{t: String, v: value{s: tok.text}}, nil
+	case tokIdent:
+		p.next()
+		switch tok.text {
+		case "true", "false":
+			return &literalNode{t: Bool, v: value{b: tok.text == "true"}}, nil
+		}
+		f, ok := fields[tok.text]
+		if !ok {
+			return nil, p.errorf(tok.pos, "unknown field %q (known: %s)", tok.text, strings.Join(Fields(), ", "))
+		}
+		p.used[tok.text] = true
+		return &fieldNode{field: f}, nil
+	}
+	return nil, p.errorf(tok.pos, "expected a field or value but found %s", tok)
+}
+
+// node is a type-checked expression tree node
+type node interface {
+	typ() Type
+	eval(*Channel) value
+}
+
+type literalNode struct {
+	t Type
+	v value
+}
+
+func (n *literalNode) typ() Type           { return n.t }
+func (n *literalNode) eval(*Channel) value { return n.v }
+
+type fieldNode struct {
+	field field
+}
+
+func (n *fieldNode) typ() Type             { return n.field.typ }
+func (n *fieldNode) eval(c *Channel) value { return n.field.get(c) }
+
+type notNode struct {
+	operand node
+}
+
+func (n *notNode) typ() Type             { return Bool }
+func (n *notNode) eval(c *Channel) value { return value{b: !n.operand.eval(c).b} }
+
+type logicalNode struct {
+	and         bool
+	left, right node
+}
+
+func (n *logicalNode) typ() Type { return Bool }
+func (n *logicalNode) eval(c *Channel) value {
+	left := n.left.eval(c).b
+	if n.and {
+		return value{b: left && n.right.eval(c).b}
+	}
+	return value{b: left || n.right.eval(c).b}
+}
+
+type matchNode struct {
+	negate  bool
+	operand node
+	re      *regexp.Regexp
+}
+
+func (n *matchNode) typ() Type { return Bool }
+func (n *matchNode) eval(c *Channel) value {
+	return value{b: n.re.MatchString(n.operand.eval(c).s) != n.negate}
+}
+
+type compareNode struct {
+	op          string
+	left, right node
+}
+
+func (n *compareNode) typ() Type { return Bool }
+func (n *compareNode) eval(c *Channel) value {
+	cmp := compare(n.left.typ(), n.left.eval(c), n.right.eval(c))
+	switch n.op {
+	case "==":
+		return value{b: cmp == 0}
+	case "!=":
+		return value{b: cmp != 0}
+	case "<":
+		return value{b: cmp < 0}
+	case "<=":
+		return value{b: cmp <= 0}
+	case ">":
+		return value{b: cmp > 0}
+	}
+	return value{b: cmp >= 0}
+}
+
+// compare orders two values of type t: -1, 0 or 1. Dates compare by day.
+func compare(t Type, a, b value) int {
+	switch t {
+	case Number:
+		switch {
+		case a.n < b.n:
+			return -1
+		case a.n > b.n:
+			return 1
+		}
+		return 0
+	case String:
+		return strings.Compare(a.s, b.s)
+	case Bool:
+		if a.b == b.b {
+			return 0
+		}
+		if !a.b {
+			return -1
+		}
+		return 1
+	}
+	da, db := a.t.Local().Format("2006-01-02"), b.t.Local().Format("2006-01-02")
+	return strings.Compare(da, db)
+}
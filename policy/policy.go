@@ -0,0 +1,142 @@
+// Package policy implements the expression language that decides which channels
+// are reported as stale, for example
+//
+//	days_idle > 45 && members < 5 && !is_shared
+//
+// Expressions combine channel fields with comparisons (==, !=, <, <=, >, >=),
+// regular expression matches (=~, !~), !, && and || and parentheses. Strings are
+// quoted with "..." or '...'; a string compared with the created field is a date
+// such as "2024-01-31".
+package policy
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Channel holds the fields an expression can refer to
+type Channel struct {
+	Name        string
+	Type        string // "public" or "private"
+	DaysIdle    int    // Whole days since the last activity, or since creation without any
+	Members     int
+	Creator     string // User ID of whoever created the channel
+	Created     time.Time
+	Topic       string
+	Purpose     string
+	IsShared    bool // Shared with another workspace of the same organization or another organization
+	IsExtShared bool // Shared with another organization
+}
+
+// Type is the type of a field or subexpression
+type Type int
+
+const (
+	Number Type = iota
+	String
+	Bool
+	Date
+)
+
+func (t Type) String() string {
+	switch t {
+	case Number:
+		return "number"
+	case String:
+		return "string"
+	case Bool:
+		return "bool"
+	}
+	return "date"
+}
+
+// field is a channel field available to expressions
+type field struct {
+	typ Type
+	get func(*Channel) value
+}
+
+var fields = map[string]field{
+	"name":          {String, func(c *Channel) value { return value{s: c.Name} }},
+	"type":          {String, func(c *Channel) value { return value{s: c.Type} }},
+	"days_idle":     {Number, func(c *Channel) value { return value{n: float64(c.DaysIdle)} }},
+	"members":       {Number, func(c *Channel) value { return value{n: float64(c.Members)} }},
+	"creator":       {String, func(c *Channel) value { return value{s: c.Creator} }},
+	"created":       {Date, func(c *Channel) value { return value{t: c.Created} }},
+	"topic":         {String, func(c *Channel) value { return value{s: c.Topic} }},
+	"purpose":       {String, func(c *Channel) value { return value{s: c.Purpose} }},
+	"is_shared":     {Bool, func(c *Channel) value { return value{b: c.IsShared} }},
+	"is_ext_shared": {Bool, func(c *Channel) value { return value{b: c.IsExtShared} }},
+}
+
+// Fields returns the names of the fields expressions can use, sorted
+func Fields() []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// value is the result of evaluating a node; only the member of its type is set
+type value struct {
+	n float64
+	s string
+	b bool
+	t time.Time
+}
+
+// Expr is a parsed policy expression
+type Expr struct {
+	src  string
+	root node
+	used map[string]bool
+}
+
+// Parse parses and type-checks an expression. Errors are of type *Error.
+func Parse(src string) (*Expr, error) {
+	p := &parser{lex: lexer{src: src}, used: make(map[string]bool)}
+	p.next()
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.errorf(p.tok.pos, "unexpected %s", p.tok)
+	}
+	if root.typ() != Bool {
+		return nil, &Error{Src: src, Col: 1, Msg: fmt.Sprintf("expression is a %s, not a condition", root.typ())}
+	}
+	return &Expr{src: src, root: root, used: p.used}, nil
+}
+
+// Eval reports whether the channel satisfies the expression
+func (e *Expr) Eval(ch Channel) bool {
+	return e.root.eval(&ch).b
+}
+
+// Uses reports whether the expression refers to the named field
+func (e *Expr) Uses(field string) bool {
+	return e.used[field]
+}
+
+// String returns the expression as written
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Error is a syntax or type error in an expression
+type Error struct {
+	Src string // The expression
+	Col int    // 1-based column, in characters, where the problem is
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Col, e.Msg)
+}
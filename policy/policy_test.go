@@ -0,0 +1,85 @@
+package policy
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEval(t *testing.T) {
+	ch := Channel{
+		Name:     "proj-apollo",
+		Type:     "public",
+		DaysIdle: 50,
+		Members:  3,
+		Creator:  "U1",
+		Created:  time.Date(2023, 6, 1, 12, 0, 0, 0, time.Local),
+		Topic:    "Apollo launch",
+		IsShared: true,
+	}
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`days_idle > 45 && members < 5 && !is_shared`, false},
+		{`days_idle > 45 && members < 5 && is_shared`, true},
+		{`days_idle >= 50 && days_idle <= 50`, true},
+		{`name =~ "^proj-" && type == 'public'`, true},
+		{`topic !~ "(?i)launch"`, false},
+		{`created < "2024-01-01" && created == "2023-06-01"`, true},
+		{`creator != "U1" || (members == 3 && !is_ext_shared)`, true},
+		{`!(is_shared == true)`, false},
+		{`purpose == ""`, true},
+	}
+	for _, tt := range tests {
+		expr, err := Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := expr.Eval(ch); got != tt.want {
+			t.Errorf("%s = %t, want %t", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		col  int
+	}{
+		{`days_idle > 45 &&`, 18},
+		{`days_idel > 45`, 1},
+		{`members < "five"`, 9},
+		{`days_idle = 45`, 11},
+		{`name =~ "("`, 9},
+		{`created < "last year"`, 11},
+		{`(members > 1`, 13},
+		{`members`, 1},
+		{`name == "open`, 9},
+		{`!members`, 2},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.expr)
+		var perr *Error
+		if !errors.As(err, &perr) {
+			t.Errorf("Parse(%q) = %v, want a parse error", tt.expr, err)
+			continue
+		}
+		if perr.Col != tt.col {
+			t.Errorf("Parse(%q): error at column %d (%v), want %d", tt.expr, perr.Col, err, tt.col)
+		}
+	}
+}
+
+func TestUses(t *testing.T) {
+	expr, err := Parse(`members < 5 && (days_idle > 45 || name =~ "^tmp-")`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	for field, want := range map[string]bool{"members": true, "days_idle": true, "name": true, "topic": false} {
+		if got := expr.Uses(field); got != want {
+			t.Errorf("Uses(%q) = %t, want %t", field, got, want)
+		}
+	}
+}
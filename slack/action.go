@@ -0,0 +1,127 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+)
+
+// Action is what to do with a stale channel
+type Action string
+
+const (
+	ActionLeave   Action = "leave"   // Remove the current user from the channel
+	ActionArchive Action = "archive" // Archive the channel for everyone; needs admin rights
+	ActionNotice  Action = "notice"  // Post a pre-archive notice; the channel is archived by ResolveNotices later
+	ActionMute    Action = "mute"    // Mute the channel for the current user but stay a member
+)
+
+// ParseAction validates an action name; the empty string means ActionLeave
+func ParseAction(s string) (Action, error) {
+	switch Action(s) {
+	case "", ActionLeave:
+		return ActionLeave, nil
+	case ActionArchive:
+		return ActionArchive, nil
+	case ActionNotice:
+		return ActionNotice, nil
+	case ActionMute:
+		return ActionMute, nil
+	}
+	return "", fmt.Errorf("unknown action %q (must be %q, %q, %q or %q)", s, ActionLeave, ActionMute, ActionArchive, ActionNotice)
+}
+
+// Done describes a channel the action succeeded on, e.g. "left"
+func (a Action) Done() string {
+	switch a {
+	case ActionArchive:
+		return "archived"
+	case ActionNotice:
+		return "notified"
+	case ActionMute:
+		return "muted"
+	}
+	return "left"
+}
+
+// ForEveryone reports whether the action affects every member of the channel rather
+// than only the current user. Such actions need channels that are stale for everyone.
+func (a Action) ForEveryone() bool {
+	return a == ActionArchive || a == ActionNotice
+}
+
+// reasonGeneral is why the general channel is skipped by archiving actions
+const reasonGeneral = "the workspace's general channel can't be archived"
+
+// skipReason tells why an action must leave a channel alone: its activity couldn't be
+// determined, it is protected by the skip list or, when archiving, it is the general channel
+func (c *Cleaner) skipReason(ch ChannelInfo, archiving bool) (string, bool) {
+	if archiving && ch.IsGeneral {
+		return reasonGeneral, true
+	}
+	if ch.Undetermined() {
+		return fmt.Sprintf("its activity could not be determined (%s), so it may still be in use", ch.Error), true
+	}
+	if pattern, ok := c.SkipList.Match(ch.ID, ch.Name); ok {
+		return fmt.Sprintf("protected by skip list entry %q", pattern), true
+	}
+	return "", false
+}
+
+// Apply runs action on every given channel
+func (c *Cleaner) Apply(action Action, channels []ChannelInfo) *LeaveReport {
+	switch action {
+	case ActionArchive:
+		return c.ArchiveChannels(channels)
+	case ActionNotice:
+		return c.PostNotices(channels)
+	case ActionMute:
+		return c.MuteChannels(channels)
+	}
+	return c.LeaveChannels(channels)
+}
+
+// ArchiveChannels archives every given channel for the whole workspace and reports
+// the outcome for each. The general channel can never be archived and is skipped,
+// as are skip-listed channels and channels whose activity the scan couldn't
+// determine. Archived channels are not journaled: they can be
+// unarchived from the workspace client by an admin.
+func (c *Cleaner) ArchiveChannels(channels []ChannelInfo) *LeaveReport {
+	if err := c.SkipListErr(); err != nil {
+		return failAll(ActionArchive, channels, err)
+	}
+	report := &LeaveReport{Action: ActionArchive}
+	for i, ch := range channels {
+		if c.Verbose {
+			fmt.Fprintf(c.Out, "➡️  [%d/%d] Archiving #%s (ID: %s)...\n", i+1, len(channels), ch.Name, ch.ID)
+		}
+
+		if reason, ok := c.skipReason(ch, true); ok {
+			report.Skipped = append(report.Skipped, LeaveSkip{Channel: ch, Reason: reason})
+			continue
+		}
+
+		err := c.call(context.Background(), "conversations.archive", func() error {
+			return c.API.ArchiveConversation(ch.ID)
+		})
+		if err != nil {
+			switch err.Error() {
+			case "cant_archive_general":
+				report.Skipped = append(report.Skipped, LeaveSkip{Channel: ch, Reason: reasonGeneral})
+			case "already_archived":
+				report.Skipped = append(report.Skipped, LeaveSkip{Channel: ch, Reason: "already archived"})
+			default:
+				if c.Verbose {
+					fmt.Fprintf(c.Out, "❌ Failed to archive #%s: %v\n", ch.Name, err)
+				}
+				report.Failed = append(report.Failed, LeaveFailure{Channel: ch, Err: err})
+			}
+			continue
+		}
+
+		report.Succeeded = append(report.Succeeded, ch)
+		if c.Verbose {
+			fmt.Fprintf(c.Out, "✅ Archived #%s\n", ch.Name)
+		}
+	}
+	return report
+}
@@ -0,0 +1,61 @@
+package slack
+
+import (
+	"testing"
+
+	"workspace-channels-cleaner/slack/fakeapi"
+)
+
+func TestArchiveRefusesGeneralChannel(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	addStaleChannels(srv, 1)
+	general := fakeapi.Channel("CGEN", "general", false)
+	general.IsGeneral = true
+	srv.AddChannel(general)
+
+	c := newTestCleaner(t, srv)
+	report := c.ArchiveChannels([]ChannelInfo{
+		{ID: "C000", Name: "stale-0", Type: "public"},
+		{ID: "CGEN", Name: "general", Type: "public", IsGeneral: true},
+		{ID: "CGEN", Name: "general", Type: "public"}, // Not flagged by the scan; the API refuses it
+	})
+
+	if report.Action != ActionArchive {
+		t.Errorf("report action is %q, want %q", report.Action, ActionArchive)
+	}
+	if len(report.Succeeded) != 1 || !srv.IsArchived("C000") {
+		t.Errorf("got %d archived, want stale-0 archived", len(report.Succeeded))
+	}
+	if len(report.Skipped) != 2 || len(report.Failed) != 0 {
+		t.Fatalf("got %d skipped and %d failed, want the general channel skipped twice", len(report.Skipped), len(report.Failed))
+	}
+	if srv.IsArchived("CGEN") {
+		t.Error("general channel was archived")
+	}
+	if n := srv.Calls("conversations.archive"); n != 2 {
+		t.Errorf("got %d archive calls, want 2 (the flagged general channel is never sent)", n)
+	}
+}
+
+func TestArchiveRefusesUndeterminedChannels(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	addStaleChannels(srv, 2)
+
+	c := newTestCleaner(t, srv)
+	report := c.ArchiveChannels([]ChannelInfo{
+		{ID: "C000", Name: "stale-0", Type: "public"},
+		{ID: "C001", Name: "stale-1", Type: "public", Error: "internal_error"},
+	})
+
+	if len(report.Succeeded) != 1 || report.Succeeded[0].ID != "C000" {
+		t.Errorf("archived %+v, want only stale-0", report.Succeeded)
+	}
+	if len(report.Skipped) != 1 || report.Skipped[0].Channel.ID != "C001" {
+		t.Fatalf("skipped %+v, want the undetermined stale-1", report.Skipped)
+	}
+	if srv.IsArchived("C001") || srv.Calls("conversations.archive") != 1 {
+		t.Error("the undetermined channel was sent to the archive API")
+	}
+}
@@ -0,0 +1,288 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// historyPageSize is how many messages are fetched per conversations.history call
+const historyPageSize = 100
+
+// ActivityFilter decides which messages count as channel activity
+type ActivityFilter struct {
+	IgnoreSubtypes map[string]bool // Message subtypes such as bot_message or channel_join
+	IgnoreUsers    map[string]bool // User or bot IDs whose messages never count
+}
+
+// NewActivityFilter builds a filter from lists of ignored subtypes and user or bot IDs
+func NewActivityFilter(ignoreSubtypes, ignoreUsers []string) ActivityFilter {
+	f := ActivityFilter{
+		IgnoreSubtypes: make(map[string]bool),
+		IgnoreUsers:    make(map[string]bool),
+	}
+	for _, s := range ignoreSubtypes {
+		f.IgnoreSubtypes[s] = true
+	}
+	for _, u := range ignoreUsers {
+		f.IgnoreUsers[u] = true
+	}
+	return f
+}
+
+// Counts reports whether a message counts as activity
+func (f ActivityFilter) Counts(msg slack.Message) bool {
+	if f.IgnoreSubtypes[msg.SubType] {
+		return false
+	}
+	// Apps can post without the bot_message subtype, so treat any bot_id as a bot post
+	if msg.BotID != "" && f.IgnoreSubtypes["bot_message"] {
+		return false
+	}
+	if f.IgnoreUsers[msg.User] || (msg.BotID != "" && f.IgnoreUsers[msg.BotID]) {
+		return false
+	}
+	return true
+}
+
+// CountsReplies reports whether any reply to a thread parent may count as activity.
+// Only the repliers are known, so this is false only when every replier is ignored;
+// otherwise the replies themselves must be checked with Counts.
+func (f ActivityFilter) CountsReplies(parent slack.Message) bool {
+	if len(parent.ReplyUsers) == 0 {
+		return true
+	}
+	for _, u := range parent.ReplyUsers {
+		if !f.IgnoreUsers[u] {
+			return true
+		}
+	}
+	return false
+}
+
+// Activity sources reported in ChannelInfo.ActivitySource
+const (
+	SourceMessage     = "message"
+	SourceThreadReply = "thread_reply"
+)
+
+// ChannelActivity is what a history scan learned about a channel
+type ChannelActivity struct {
+	LastSeen   time.Time // Newest activity by anyone; zero if nothing counts as activity
+	Source     string    // Whether LastSeen came from a message or a thread reply
+	MyLastSeen time.Time // Newest post or reaction by the authenticated user; only scanned with SelfActivity
+}
+
+// CheckChannel pages back through a channel's history to find its last activity.
+// It stops at the first page with a message that counts or once it has passed
+// cutoff, normally Cutoff or that of the channel's rule (see CutoffFor). If the
+// cutoff is passed first, the newest message older than the cutoff is used
+// instead, which is an upper bound for the last real activity. With
+// IncludeThreads, the replies to every parent on those pages whose latest_reply
+// is newer than the activity found so far are fetched, and the newest one that
+// counts is considered too. With SelfActivity it keeps paging until it finds the authenticated user's
+// newest post or reaction, or has passed the cutoff without one; the user's replies
+// count too, in threads whose parent is on a page that was fetched. It only reads
+// the cleaner, so scan workers can share it: UserID must already be resolved, as
+// GetFilteredChannels and RecheckChannel do before checking channels.
+func (c *Cleaner) CheckChannel(ctx context.Context, channelID string, cutoff time.Time) (ChannelActivity, error) {
+	var activity ChannelActivity
+	channelDone, selfDone := false, !c.SelfActivity
+	cursor := ""
+	for {
+		var history *slack.GetConversationHistoryResponse
+		err := c.call(ctx, "conversations.history", func() (err error) {
+			history, err = c.API.GetConversationHistoryContext(ctx, &slack.GetConversationHistoryParameters{
+				ChannelID: channelID,
+				Cursor:    cursor,
+				Limit:     historyPageSize,
+			})
+			return err
+		})
+		if err != nil {
+			return ChannelActivity{}, err
+		}
+
+		var newestBeforeCutoff time.Time
+		for _, msg := range history.Messages {
+			ts, err := parseTimestamp(msg.Timestamp)
+			if err != nil {
+				return ChannelActivity{}, err
+			}
+			if ts.Before(cutoff) && newestBeforeCutoff.IsZero() {
+				newestBeforeCutoff = ts
+			}
+			// Messages come newest first, so the first one by the user is their newest
+			if !selfDone && c.ownActivity(msg) {
+				activity.MyLastSeen = ts
+				selfDone = true
+			}
+			if c.SelfActivity && msg.LatestReply != "" && containsString(msg.ReplyUsers, c.UserID) {
+				threadTS, err := parseTimestamp(msg.LatestReply)
+				if err != nil {
+					return ChannelActivity{}, err
+				}
+				// A thread that ended before the user's newest known activity can't change it
+				if threadTS.After(activity.MyLastSeen) {
+					replyTS, err := c.latestReply(ctx, channelID, msg, c.ownReply)
+					if err != nil {
+						return ChannelActivity{}, err
+					}
+					if replyTS.After(activity.MyLastSeen) {
+						activity.MyLastSeen = replyTS
+					}
+				}
+			}
+			if channelDone {
+				continue
+			}
+			if c.Activity.Counts(msg) && ts.After(activity.LastSeen) {
+				activity.LastSeen, activity.Source = ts, SourceMessage
+			}
+			if c.IncludeThreads && msg.LatestReply != "" && c.Activity.CountsReplies(msg) {
+				threadTS, err := parseTimestamp(msg.LatestReply)
+				if err != nil {
+					return ChannelActivity{}, err
+				}
+				// The latest reply may be a bot's, so the thread is read for the newest one that counts
+				if threadTS.After(activity.LastSeen) {
+					replyTS, err := c.latestReply(ctx, channelID, msg, c.Activity.Counts)
+					if err != nil {
+						return ChannelActivity{}, err
+					}
+					if replyTS.After(activity.LastSeen) {
+						activity.LastSeen, activity.Source = replyTS, SourceThreadReply
+					}
+				}
+			}
+		}
+
+		if !channelDone {
+			if activity.LastSeen.IsZero() && !newestBeforeCutoff.IsZero() {
+				activity.LastSeen, activity.Source = newestBeforeCutoff, SourceMessage
+			}
+			channelDone = !activity.LastSeen.IsZero()
+		}
+		if !newestBeforeCutoff.IsZero() {
+			// Anything the user did further back is older than the cutoff anyway
+			selfDone = true
+		}
+		if channelDone && selfDone {
+			return activity, nil
+		}
+		if !history.HasMore || history.ResponseMetaData.NextCursor == "" {
+			return activity, nil
+		}
+		cursor = history.ResponseMetaData.NextCursor
+	}
+}
+
+// IsStale reports whether a channel with the given activity is a candidate to leave
+// at the given cutoff
+func (c *Cleaner) IsStale(activity ChannelActivity, cutoff time.Time) bool {
+	if activity.LastSeen.IsZero() {
+		return c.IncludeEmpty
+	}
+	if c.SelfActivity {
+		return activity.MyLastSeen.Before(cutoff)
+	}
+	return activity.LastSeen.Before(cutoff)
+}
+
+// ownActivity reports whether msg was posted or reacted to by the authenticated user.
+// Reactions carry no timestamp, so a reaction counts at the time of its message.
+func (c *Cleaner) ownActivity(msg slack.Message) bool {
+	if msg.User == c.UserID && c.Activity.Counts(msg) {
+		return true
+	}
+	for _, reaction := range msg.Reactions {
+		for _, user := range reaction.Users {
+			if user == c.UserID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ownReply reports whether msg is a reply by the authenticated user that counts as activity
+func (c *Cleaner) ownReply(msg slack.Message) bool {
+	return msg.User == c.UserID && c.Activity.Counts(msg)
+}
+
+// latestReply returns when the newest reply accepted by keep was posted in the
+// thread of parent, or the zero time
+func (c *Cleaner) latestReply(ctx context.Context, channelID string, parent slack.Message, keep func(slack.Message) bool) (time.Time, error) {
+	var latest time.Time
+	cursor := ""
+	for {
+		var (
+			msgs    []slack.Message
+			hasMore bool
+			next    string
+		)
+		err := c.call(ctx, "conversations.replies", func() (err error) {
+			msgs, hasMore, next, err = c.API.GetConversationRepliesContext(ctx, &slack.GetConversationRepliesParameters{
+				ChannelID: channelID,
+				Timestamp: parent.Timestamp,
+				Cursor:    cursor,
+				Limit:     historyPageSize,
+			})
+			return err
+		})
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to fetch thread %s: %w", parent.Timestamp, err)
+		}
+
+		for _, msg := range msgs {
+			if msg.Timestamp == parent.Timestamp || !keep(msg) {
+				continue
+			}
+			ts, err := parseTimestamp(msg.Timestamp)
+			if err != nil {
+				return time.Time{}, err
+			}
+			if ts.After(latest) {
+				latest = ts
+			}
+		}
+
+		cursor = next
+		if !hasMore || cursor == "" {
+			return latest, nil
+		}
+	}
+}
+
+// resolveIdentity looks up the authenticated user and workspace via auth.test when
+// SelfActivity or the scan cache needs them. It writes UserID and TeamID, so it must
+// run before any scan worker starts.
+func (c *Cleaner) resolveIdentity(ctx context.Context) error {
+	needUser := c.SelfActivity && c.UserID == ""
+	needTeam := c.CachePath != "" && c.TeamID == ""
+	if !needUser && !needTeam {
+		return nil
+	}
+	var resp *slack.AuthTestResponse
+	err := c.call(ctx, "auth.test", func() (err error) {
+		resp, err = c.API.AuthTestContext(ctx)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to identify the authenticated user: %w", err)
+	}
+	c.UserID, c.TeamID = resp.UserID, resp.TeamID
+	return nil
+}
+
+// parseTimestamp converts a message timestamp such as "1700000000.000100" to a time
+func parseTimestamp(ts string) (time.Time, error) {
+	tsFloat, err := strconv.ParseFloat(ts, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid message timestamp %q: %w", ts, err)
+	}
+	return time.Unix(int64(tsFloat), 0), nil
+}
@@ -0,0 +1,193 @@
+package slack
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"workspace-channels-cleaner/config"
+	"workspace-channels-cleaner/slack/fakeapi"
+)
+
+// daysAgo returns the time n days before now
+func daysAgo(n int) time.Time {
+	return time.Now().AddDate(0, 0, -n)
+}
+
+// scanIDs scans and returns the IDs of the reported channels in order
+func scanIDs(t *testing.T, c *Cleaner) []string {
+	t.Helper()
+	channels, err := c.GetFilteredChannels(context.Background())
+	if err != nil {
+		t.Fatalf("GetFilteredChannels: %v", err)
+	}
+	ids := make([]string, 0, len(channels))
+	for _, ch := range channels {
+		ids = append(ids, ch.ID)
+	}
+	return ids
+}
+
+func TestBotMessagesDontCountAsActivity(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	// More bot posts than fit on a history page, all after the cutoff
+	msgs := []slack.Message{fakeapi.Message("U1", daysAgo(60))}
+	for i := 0; i < historyPageSize+50; i++ {
+		msgs = append(msgs, fakeapi.BotMessage("B1", daysAgo(1).Add(-time.Duration(i)*time.Minute)))
+	}
+	srv.AddChannel(fakeapi.Channel("C1", "bots-only", false), msgs...)
+	srv.AddChannel(fakeapi.Channel("C2", "joins-and-talk", false),
+		fakeapi.EventMessage("channel_join", "U2", daysAgo(1)), fakeapi.Message("U1", daysAgo(5)))
+
+	c := newTestCleaner(t, srv)
+	c.Activity = NewActivityFilter(config.DefaultIgnoredSubtypes, nil)
+	channels, err := c.GetFilteredChannels(context.Background())
+	if err != nil {
+		t.Fatalf("GetFilteredChannels: %v", err)
+	}
+	if len(channels) != 1 || channels[0].ID != "C1" {
+		t.Fatalf("got %+v, want only bots-only", channels)
+	}
+	if want := daysAgo(60).Unix(); channels[0].LastSeen.Unix() != want {
+		t.Errorf("bots-only last seen %v, want the human post before the bots", channels[0].LastSeen)
+	}
+	if calls := srv.Calls("conversations.history"); calls != 3 {
+		t.Errorf("made %d history calls, want 2 pages for bots-only and 1 for joins-and-talk", calls)
+	}
+
+	// Without the filter the bots keep the channel alive
+	c.Activity = ActivityFilter{}
+	if ids := scanIDs(t, c); len(ids) != 0 {
+		t.Errorf("got %v with every message counting, want none", ids)
+	}
+}
+
+func TestIgnoredUsersDontCountAsActivity(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	srv.AddChannel(fakeapi.Channel("C1", "reminders", false),
+		fakeapi.Message("UREMIND", daysAgo(1)), fakeapi.Message("U1", daysAgo(60)))
+	srv.AddChannel(fakeapi.Channel("C2", "app-posts", false),
+		fakeapi.BotMessage("BAPP", daysAgo(1)), fakeapi.Message("U1", daysAgo(60)))
+	srv.AddChannel(fakeapi.Channel("C3", "people", false),
+		fakeapi.Message("UREMIND", daysAgo(1)), fakeapi.Message("U1", daysAgo(2)))
+
+	c := newTestCleaner(t, srv)
+	c.Activity = NewActivityFilter(nil, []string{"UREMIND", "BAPP"})
+	if ids := scanIDs(t, c); len(ids) != 2 || ids[0] == "C3" || ids[1] == "C3" {
+		t.Errorf("got %v, want reminders and app-posts", ids)
+	}
+}
+
+func TestRecentThreadRepliesKeepChannelsActive(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	lively, old := fakeapi.Message("U1", daysAgo(60)), fakeapi.Message("U1", daysAgo(60))
+	srv.AddChannel(fakeapi.Channel("C1", "lively-thread", false), lively)
+	srv.AddChannel(fakeapi.Channel("C2", "old-thread", false), old)
+	srv.AddReplies("C1", lively.Timestamp, fakeapi.Message("U2", daysAgo(2)))
+	srv.AddReplies("C2", old.Timestamp, fakeapi.Message("U2", daysAgo(45)))
+
+	c := newTestCleaner(t, srv)
+	if ids := scanIDs(t, c); len(ids) != 2 {
+		t.Errorf("got %v without IncludeThreads, want both channels", ids)
+	}
+
+	c.IncludeThreads = true
+	channels, err := c.GetFilteredChannels(context.Background())
+	if err != nil {
+		t.Fatalf("GetFilteredChannels: %v", err)
+	}
+	if len(channels) != 1 || channels[0].ID != "C2" {
+		t.Fatalf("got %+v with IncludeThreads, want only old-thread", channels)
+	}
+	if channels[0].ActivitySource != SourceThreadReply || channels[0].LastSeen.Unix() != daysAgo(45).Unix() {
+		t.Errorf("old-thread last seen %v from %q, want its latest reply", channels[0].LastSeen, channels[0].ActivitySource)
+	}
+}
+
+func TestThreadRepliesByIgnoredUsersDontCount(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	botReplies := fakeapi.Message("U1", daysAgo(60))
+	mixed := fakeapi.Message("U1", daysAgo(60))
+	srv.AddChannel(fakeapi.Channel("C1", "bot-replies", false), botReplies)
+	srv.AddChannel(fakeapi.Channel("C2", "mixed-replies", false), mixed)
+	srv.AddReplies("C1", botReplies.Timestamp, fakeapi.Message("UBOT", daysAgo(2)), fakeapi.Message("UREMIND", daysAgo(1)))
+	srv.AddReplies("C2", mixed.Timestamp, fakeapi.Message("U2", daysAgo(2)), fakeapi.Message("UBOT", daysAgo(1)))
+
+	c := newTestCleaner(t, srv)
+	c.IncludeThreads = true
+	c.Activity = NewActivityFilter(nil, []string{"UBOT", "UREMIND"})
+	channels, err := c.GetFilteredChannels(context.Background())
+	if err != nil {
+		t.Fatalf("GetFilteredChannels: %v", err)
+	}
+	if len(channels) != 1 || channels[0].ID != "C1" || channels[0].ActivitySource != SourceMessage {
+		t.Errorf("got %+v, want only bot-replies, judged by its parent message", channels)
+	}
+	// Every replier of bot-replies is ignored, so its thread isn't even read
+	if calls := srv.Calls("conversations.replies"); calls != 1 {
+		t.Errorf("made %d replies calls, want 1 for mixed-replies", calls)
+	}
+}
+
+func TestBotRepliesDontHideAnOldHumanReply(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	parent := fakeapi.Message("U1", daysAgo(90))
+	srv.AddChannel(fakeapi.Channel("C1", "bot-replied-last", false), parent)
+	srv.AddReplies("C1", parent.Timestamp, fakeapi.Message("U2", daysAgo(60)), fakeapi.BotMessage("B1", daysAgo(1)))
+
+	c := newTestCleaner(t, srv)
+	c.IncludeThreads = true
+	c.Activity = NewActivityFilter(config.DefaultIgnoredSubtypes, nil)
+	channels, err := c.GetFilteredChannels(context.Background())
+	if err != nil {
+		t.Fatalf("GetFilteredChannels: %v", err)
+	}
+	if len(channels) != 1 || channels[0].ID != "C1" {
+		t.Fatalf("got %+v, want bot-replied-last", channels)
+	}
+	if channels[0].ActivitySource != SourceThreadReply || channels[0].LastSeen.Unix() != daysAgo(60).Unix() {
+		t.Errorf("last seen %v from %q, want the human reply", channels[0].LastSeen, channels[0].ActivitySource)
+	}
+}
+
+func TestSelfActivityJudgesChannelsByTheUsersOwnActivity(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	srv.SetUser("UME")
+	srv.AddChannel(fakeapi.Channel("C1", "i-posted", false),
+		fakeapi.Message("U2", daysAgo(1)), fakeapi.Message("UME", daysAgo(5)))
+	srv.AddChannel(fakeapi.Channel("C2", "i-reacted", false),
+		fakeapi.Message("U2", daysAgo(1)), fakeapi.WithReaction(fakeapi.Message("U2", daysAgo(3)), "eyes", "U3", "UME"))
+	srv.AddChannel(fakeapi.Channel("C3", "i-went-quiet", false),
+		fakeapi.Message("U2", daysAgo(1)), fakeapi.Message("UME", daysAgo(60)))
+	srv.AddChannel(fakeapi.Channel("C4", "never-posted", false), fakeapi.Message("U2", daysAgo(1)))
+	parent := fakeapi.Message("U2", daysAgo(20))
+	srv.AddChannel(fakeapi.Channel("C5", "i-replied", false), fakeapi.Message("U2", daysAgo(1)), parent)
+	srv.AddReplies("C5", parent.Timestamp, fakeapi.Message("UME", daysAgo(2)), fakeapi.Message("U2", daysAgo(1)))
+
+	c := newTestCleaner(t, srv)
+	c.SelfActivity = true
+	channels, err := c.GetFilteredChannels(context.Background())
+	if err != nil {
+		t.Fatalf("GetFilteredChannels: %v", err)
+	}
+	if len(channels) != 2 || channels[0].ID != "C4" || channels[1].ID != "C3" {
+		t.Fatalf("got %+v, want never-posted then i-went-quiet", channels)
+	}
+	if !channels[0].MyLastSeen.IsZero() {
+		t.Errorf("never-posted has own activity at %v, want none", channels[0].MyLastSeen)
+	}
+	if channels[1].MyLastSeen.Unix() != daysAgo(60).Unix() || channels[1].LastSeen.Unix() != daysAgo(1).Unix() {
+		t.Errorf("i-went-quiet seen %v by me and %v by anyone, want 60 and 1 days ago", channels[1].MyLastSeen, channels[1].LastSeen)
+	}
+	if c.UserID != "UME" {
+		t.Errorf("scanned as %q, want the user auth.test reports", c.UserID)
+	}
+}
@@ -0,0 +1,36 @@
+package slack
+
+import (
+	"time"
+
+	"workspace-channels-cleaner/config"
+)
+
+// NewCleanerFromConfig creates a cleaner for the given application configuration
+func NewCleanerFromConfig(token string, appConfig *config.AppConfig) *Cleaner {
+	c := NewCleaner(token, appConfig.Limit, GetChannelTypes(appConfig.Types), appConfig.Days, appConfig.Verbose)
+	c.Configure(appConfig)
+	return c
+}
+
+// Configure applies the settings of appConfig that NewCleaner doesn't take as arguments
+func (c *Cleaner) Configure(appConfig *config.AppConfig) {
+	c.Activity = NewActivityFilter(appConfig.Activity.IgnoreSubtypes, appConfig.Activity.IgnoreUsers)
+	c.IncludeEmpty = appConfig.IncludeEmpty
+	c.IncludeThreads = appConfig.IncludeThreads
+	c.SelfActivity = appConfig.Staleness == config.StalenessSelf
+	c.NoticeMessage = appConfig.Notice.Message
+	c.GraceDays = appConfig.Notice.GraceDays
+	c.Include = appConfig.Include
+	c.Exclude = appConfig.Exclude
+	c.Rules = appConfig.Rules
+	c.Policy = appConfig.Policy
+	if appConfig.Export.Enabled {
+		c.ExportDir = appConfig.Export.Dir
+	}
+	if appConfig.Cache.Enabled {
+		c.CachePath = DefaultScanCachePath
+		c.CacheTTL = time.Duration(appConfig.Cache.TTLHours) * time.Hour
+		c.CacheMargin = time.Duration(appConfig.Cache.MarginDays) * 24 * time.Hour
+	}
+}
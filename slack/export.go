@@ -0,0 +1,241 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// ChannelExport is the JSON export of a channel's history, oldest message first
+type ChannelExport struct {
+	Channel    ChannelInfo       `json:"channel"`
+	ExportedAt time.Time         `json:"exported_at"`
+	Messages   []ExportedMessage `json:"messages"`
+}
+
+// ExportedMessage is a message with its thread replies and file metadata
+type ExportedMessage struct {
+	TS      string            `json:"ts"`
+	Time    time.Time         `json:"time"`
+	User    string            `json:"user,omitempty"`
+	BotID   string            `json:"bot_id,omitempty"`
+	SubType string            `json:"subtype,omitempty"`
+	Text    string            `json:"text"`
+	Files   []ExportedFile    `json:"files,omitempty"`
+	Replies []ExportedMessage `json:"replies,omitempty"`
+}
+
+// ExportedFile is the metadata of a shared file; the file itself isn't downloaded
+type ExportedFile struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Title     string `json:"title,omitempty"`
+	Mimetype  string `json:"mimetype,omitempty"`
+	Size      int    `json:"size"`
+	User      string `json:"user,omitempty"`
+	URL       string `json:"url_private,omitempty"`
+	Permalink string `json:"permalink,omitempty"`
+}
+
+// exportDir is the dated directory the exports of a run are written to. A run
+// computes it once, so exports don't split across two days at midnight.
+func (c *Cleaner) exportDir() string {
+	return filepath.Join(c.ExportDir, time.Now().Format("2006-01-02"))
+}
+
+// ExportChannel writes a channel's full history, thread replies included, as
+// <name>_<id>.json plus a Markdown transcript into dir, the run's dated export
+// directory. It returns the path of the JSON file.
+func (c *Cleaner) ExportChannel(ctx context.Context, dir string, ch ChannelInfo) (string, error) {
+	export, err := c.fetchExport(ctx, ch)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create export directory: %w", err)
+	}
+	base := filepath.Join(dir, fmt.Sprintf("%s_%s", ch.Name, ch.ID))
+
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal export: %w", err)
+	}
+	if err := os.WriteFile(base+".json", data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write export: %w", err)
+	}
+	if err := os.WriteFile(base+".md", []byte(export.Markdown()), 0644); err != nil {
+		return "", fmt.Errorf("failed to write transcript: %w", err)
+	}
+	return base + ".json", nil
+}
+
+// fetchExport pages through the whole history of a channel and the replies of every thread
+func (c *Cleaner) fetchExport(ctx context.Context, ch ChannelInfo) (*ChannelExport, error) {
+	export := &ChannelExport{Channel: ch, ExportedAt: time.Now().UTC(), Messages: []ExportedMessage{}}
+
+	cursor := ""
+	for {
+		var history *slack.GetConversationHistoryResponse
+		err := c.call(ctx, "conversations.history", func() (err error) {
+			history, err = c.API.GetConversationHistoryContext(ctx, &slack.GetConversationHistoryParameters{
+				ChannelID: ch.ID,
+				Cursor:    cursor,
+				Limit:     historyPageSize,
+			})
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch history: %w", err)
+		}
+
+		for _, msg := range history.Messages {
+			exported, err := exportMessage(msg)
+			if err != nil {
+				return nil, err
+			}
+			if msg.ReplyCount > 0 {
+				if exported.Replies, err = c.fetchReplies(ctx, ch.ID, msg.Timestamp); err != nil {
+					return nil, err
+				}
+			}
+			export.Messages = append(export.Messages, exported)
+		}
+
+		cursor = history.ResponseMetaData.NextCursor
+		if !history.HasMore || cursor == "" {
+			break
+		}
+	}
+
+	// History comes newest first; a transcript reads oldest first
+	sort.SliceStable(export.Messages, func(i, j int) bool {
+		return export.Messages[i].Time.Before(export.Messages[j].Time)
+	})
+	return export, nil
+}
+
+// fetchReplies returns the replies of a thread, oldest first, without the parent
+func (c *Cleaner) fetchReplies(ctx context.Context, channelID, parentTS string) ([]ExportedMessage, error) {
+	var replies []ExportedMessage
+	cursor := ""
+	for {
+		var (
+			msgs    []slack.Message
+			hasMore bool
+			next    string
+		)
+		err := c.call(ctx, "conversations.replies", func() (err error) {
+			msgs, hasMore, next, err = c.API.GetConversationRepliesContext(ctx, &slack.GetConversationRepliesParameters{
+				ChannelID: channelID,
+				Timestamp: parentTS,
+				Cursor:    cursor,
+				Limit:     historyPageSize,
+			})
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch thread %s: %w", parentTS, err)
+		}
+
+		for _, msg := range msgs {
+			if msg.Timestamp == parentTS {
+				continue
+			}
+			exported, err := exportMessage(msg)
+			if err != nil {
+				return nil, err
+			}
+			replies = append(replies, exported)
+		}
+
+		cursor = next
+		if !hasMore || cursor == "" {
+			return replies, nil
+		}
+	}
+}
+
+func exportMessage(msg slack.Message) (ExportedMessage, error) {
+	ts, err := parseTimestamp(msg.Timestamp)
+	if err != nil {
+		return ExportedMessage{}, err
+	}
+	exported := ExportedMessage{
+		TS:      msg.Timestamp,
+		Time:    ts.UTC(),
+		User:    msg.User,
+		BotID:   msg.BotID,
+		SubType: msg.SubType,
+		Text:    msg.Text,
+	}
+	for _, f := range msg.Files {
+		exported.Files = append(exported.Files, ExportedFile{
+			ID:        f.ID,
+			Name:      f.Name,
+			Title:     f.Title,
+			Mimetype:  f.Mimetype,
+			Size:      f.Size,
+			User:      f.User,
+			URL:       f.URLPrivate,
+			Permalink: f.Permalink,
+		})
+	}
+	return exported, nil
+}
+
+// Markdown renders the export as a human-readable transcript grouped by day
+func (e *ChannelExport) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# #%s\n\n", e.Channel.Name)
+	fmt.Fprintf(&b, "Channel ID %s, exported %s, %d message(s).\n", e.Channel.ID, e.ExportedAt.Format("2006-01-02 15:04 MST"), len(e.Messages))
+	if !e.Channel.Created.IsZero() {
+		fmt.Fprintf(&b, "Created %s", e.Channel.Created.Format("2006-01-02"))
+		if e.Channel.Creator != "" {
+			fmt.Fprintf(&b, " by %s", e.Channel.Creator)
+		}
+		fmt.Fprintf(&b, ", %d member(s).\n", e.Channel.NumMembers)
+	}
+	if e.Channel.Topic != "" {
+		fmt.Fprintf(&b, "\nTopic: %s\n", e.Channel.Topic)
+	}
+	if e.Channel.Purpose != "" {
+		fmt.Fprintf(&b, "\nPurpose: %s\n", e.Channel.Purpose)
+	}
+
+	day := ""
+	for _, msg := range e.Messages {
+		if d := msg.Time.Format("2006-01-02"); d != day {
+			day = d
+			fmt.Fprintf(&b, "\n## %s\n\n", day)
+		}
+		writeMarkdownMessage(&b, msg, "")
+		for _, reply := range msg.Replies {
+			writeMarkdownMessage(&b, reply, "> ")
+		}
+	}
+	return b.String()
+}
+
+func writeMarkdownMessage(b *strings.Builder, msg ExportedMessage, prefix string) {
+	author := msg.User
+	if author == "" {
+		author = msg.BotID
+	}
+	if msg.SubType != "" {
+		author += " (" + msg.SubType + ")"
+	}
+	text := strings.ReplaceAll(msg.Text, "\n", "\n"+prefix)
+	fmt.Fprintf(b, "%s**%s** %s: %s\n", prefix, author, msg.Time.Format("15:04"), text)
+	for _, f := range msg.Files {
+		fmt.Fprintf(b, "%s📎 %s (%s, %d bytes)\n", prefix, f.Name, f.Mimetype, f.Size)
+	}
+	b.WriteString(prefix + "\n")
+}
@@ -0,0 +1,78 @@
+package slack
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"workspace-channels-cleaner/slack/fakeapi"
+)
+
+func TestLeaveExportsHistoryFirst(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	old := time.Now().AddDate(0, 0, -90)
+	var msgs []slack.Message
+	for i := 0; i < 250; i++ {
+		msgs = append(msgs, fakeapi.Message("U1", old.Add(time.Duration(i)*time.Minute)))
+	}
+	msgs[10].Files = []slack.File{{ID: "F1", Name: "plan.pdf", Mimetype: "application/pdf", Size: 2048}}
+	srv.AddChannel(fakeapi.Channel("C1", "secret-project", true), msgs...)
+	parent := msgs[20].Timestamp
+	srv.AddReplies("C1", parent,
+		fakeapi.Message("U2", old.Add(time.Hour)),
+		fakeapi.Message("U3", old.Add(2*time.Hour)),
+		fakeapi.Message("U2", old.Add(3*time.Hour)),
+	)
+	srv.AddChannel(fakeapi.Channel("C2", "broken", true), fakeapi.Message("U1", old))
+	srv.BreakHistory("C2", "internal_error")
+
+	c := newTestCleaner(t, srv)
+	c.ExportDir = t.TempDir()
+	report := c.LeaveChannels([]ChannelInfo{
+		{ID: "C1", Name: "secret-project", Type: "private"},
+		{ID: "C2", Name: "broken", Type: "private"},
+	})
+
+	if len(report.Succeeded) != 1 || report.Succeeded[0].ID != "C1" {
+		t.Fatalf("left %+v, want only secret-project", report.Succeeded)
+	}
+	if len(report.Failed) != 1 || !srv.IsMember("C2") {
+		t.Errorf("channel with a failed export was left or not reported: %+v", report.Failed)
+	}
+
+	base := filepath.Join(c.ExportDir, time.Now().Format("2006-01-02"), "secret-project_C1")
+	data, err := os.ReadFile(base + ".json")
+	if err != nil {
+		t.Fatalf("reading export: %v", err)
+	}
+	var export ChannelExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		t.Fatalf("parsing export: %v", err)
+	}
+	if len(export.Messages) != 250 {
+		t.Fatalf("exported %d messages, want 250", len(export.Messages))
+	}
+	if export.Messages[0].TS != msgs[0].Timestamp {
+		t.Errorf("first exported message is %s, want the oldest", export.Messages[0].TS)
+	}
+	if files := export.Messages[10].Files; len(files) != 1 || files[0].Name != "plan.pdf" {
+		t.Errorf("file metadata %+v, want plan.pdf", files)
+	}
+	if n := len(export.Messages[20].Replies); n != 3 {
+		t.Errorf("exported %d replies, want 3", n)
+	}
+
+	transcript, err := os.ReadFile(base + ".md")
+	if err != nil {
+		t.Fatalf("reading transcript: %v", err)
+	}
+	if !strings.Contains(string(transcript), "# #secret-project") || !strings.Contains(string(transcript), "📎 plan.pdf") {
+		t.Errorf("transcript misses the title or the file:\n%s", transcript)
+	}
+}
@@ -0,0 +1,64 @@
+package fakeapi
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// Channel builds a channel that the token's user is a member of
+func Channel(id, name string, private bool) slack.Channel {
+	var ch slack.Channel
+	ch.ID = id
+	ch.Name = name
+	ch.IsChannel = true
+	ch.IsPrivate = private
+	ch.IsMember = true
+	return ch
+}
+
+// Message builds a plain message posted by user at t
+func Message(user string, t time.Time) slack.Message {
+	var msg slack.Message
+	msg.Type = "message"
+	msg.User = user
+	msg.Text = "hello"
+	msg.Timestamp = TS(t)
+	return msg
+}
+
+// TS formats t the way the API formats message timestamps
+func TS(t time.Time) string {
+	return fmt.Sprintf("%d.%06d", t.Unix(), t.Nanosecond()/1000)
+}
+
+// BotMessage builds a message posted by an app or integration at t
+func BotMessage(botID string, t time.Time) slack.Message {
+	msg := Message("", t)
+	msg.SubType = "bot_message"
+	msg.BotID = botID
+	return msg
+}
+
+// EventMessage builds a system message such as channel_join at t
+func EventMessage(subtype, user string, t time.Time) slack.Message {
+	msg := Message(user, t)
+	msg.SubType = subtype
+	return msg
+}
+
+// WithThread marks msg as a thread parent whose latest reply was posted by users at t
+func WithThread(msg slack.Message, t time.Time, users ...string) slack.Message {
+	msg.ThreadTimestamp = msg.Timestamp
+	msg.ReplyCount = len(users)
+	msg.ReplyUsers = users
+	msg.LatestReply = TS(t)
+	return msg
+}
+
+// WithReaction adds a reaction by the given users to msg
+func WithReaction(msg slack.Message, name string, users ...string) slack.Message {
+	msg.Reactions = append(msg.Reactions, slack.ItemReaction{Name: name, Count: len(users), Users: users})
+	return msg
+}
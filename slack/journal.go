@@ -0,0 +1,192 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"workspace-channels-cleaner/config"
+)
+
+// DefaultJournalPath is where successful leaves are recorded
+const DefaultJournalPath = "config/journal.json"
+
+// ErrPrivateRejoin is returned when rejoining a private channel, which the API only allows by invitation
+var ErrPrivateRejoin = errors.New("private channels can't be rejoined through the API; ask a current member to invite you back")
+
+// Selection records the settings that selected a channel for leaving
+type Selection struct {
+	Days    int       `json:"days"`
+	Cutoff  time.Time `json:"cutoff"`
+	Include []string  `json:"include,omitempty"`
+	Exclude []string  `json:"exclude,omitempty"`
+	Types   []string  `json:"types"`
+
+	// Rule is the rule that set the channel's threshold; Days and Cutoff are its own
+	Rule *RuleMatch `json:"rule,omitempty"`
+
+	// Policy is the expression that decided staleness instead of Days, if any
+	Policy string `json:"policy,omitempty"`
+
+	// Staleness is whose activity was judged: config.StalenessChannel or StalenessSelf
+	Staleness string `json:"staleness,omitempty"`
+
+	// Keyword is the single name filter of entries recorded before include lists
+	Keyword string `json:"keyword,omitempty"`
+}
+
+// JournalEntry records a channel left by the cleaner so it can be rejoined later
+type JournalEntry struct {
+	ChannelID  string     `json:"channel_id"`
+	Name       string     `json:"name"`
+	Type       string     `json:"type"`
+	LeftAt     time.Time  `json:"left_at"`
+	Selection  Selection  `json:"selection"`
+	RejoinedAt *time.Time `json:"rejoined_at,omitempty"`
+}
+
+// RejoinResult is the outcome of rejoining a single journal entry
+type RejoinResult struct {
+	Entry JournalEntry
+	Err   error
+}
+
+// LoadJournal loads the leave journal from a JSON file
+func LoadJournal(path string) ([]JournalEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []JournalEntry{}, nil // Return empty journal if file doesn't exist
+	}
+
+	var entries []JournalEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse journal: %w", err)
+	}
+	return entries, nil
+}
+
+// SaveJournal saves the leave journal to a JSON file
+func SaveJournal(path string, entries []JournalEntry) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal journal: %w", err)
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
+// PendingJournalEntries returns the entries that have not been rejoined yet
+func PendingJournalEntries(entries []JournalEntry) []JournalEntry {
+	var pending []JournalEntry
+	for _, e := range entries {
+		if e.RejoinedAt == nil {
+			pending = append(pending, e)
+		}
+	}
+	return pending
+}
+
+// recordLeave appends a journal entry for a channel that was just left
+func (c *Cleaner) recordLeave(ch ChannelInfo) error {
+	if c.JournalPath == "" {
+		return nil
+	}
+
+	entries, err := LoadJournal(c.JournalPath)
+	if err != nil {
+		return err
+	}
+	days := c.Days
+	if ch.Rule != nil {
+		days = ch.Rule.Days
+	}
+	staleness := config.StalenessChannel
+	if c.SelfActivity {
+		staleness = config.StalenessSelf
+	}
+	entries = append(entries, JournalEntry{
+		ChannelID: ch.ID,
+		Name:      ch.Name,
+		Type:      ch.Type,
+		LeftAt:    time.Now().UTC(),
+		Selection: Selection{
+			Days:      days,
+			Cutoff:    c.CutoffFor(ch.Rule).UTC(),
+			Include:   c.Include,
+			Exclude:   c.Exclude,
+			Types:     c.Types,
+			Rule:      ch.Rule,
+			Policy:    c.Policy,
+			Staleness: staleness,
+		},
+	})
+	return SaveJournal(c.JournalPath, entries)
+}
+
+// RejoinChannels rejoins the given journal entries and marks the successful ones in the journal
+func (c *Cleaner) RejoinChannels(entries []JournalEntry) []RejoinResult {
+	results := make([]RejoinResult, 0, len(entries))
+	rejoined := make(map[string]time.Time)
+
+	for i, e := range entries {
+		if c.Verbose {
+			fmt.Fprintf(c.Out, "➡️  [%d/%d] Rejoining #%s (ID: %s)...\n", i+1, len(entries), e.Name, e.ChannelID)
+		}
+
+		err := c.rejoin(e)
+		results = append(results, RejoinResult{Entry: e, Err: err})
+		if err != nil {
+			if c.Verbose {
+				fmt.Fprintf(c.Out, "❌ Could not rejoin #%s: %v\n", e.Name, err)
+			}
+			continue
+		}
+		rejoined[e.ChannelID] = time.Now().UTC()
+
+		if c.Verbose {
+			fmt.Fprintf(c.Out, "✅ Rejoined #%s\n", e.Name)
+		}
+	}
+
+	if len(rejoined) > 0 && c.JournalPath != "" {
+		if err := c.markRejoined(rejoined); err != nil && c.Verbose {
+			fmt.Fprintf(c.Out, "⚠️  Failed to update journal: %v\n", err)
+		}
+	}
+	return results
+}
+
+func (c *Cleaner) rejoin(e JournalEntry) error {
+	if e.Type == "private" {
+		return ErrPrivateRejoin
+	}
+
+	err := c.call(context.Background(), "conversations.join", func() error {
+		_, _, _, err := c.API.JoinConversation(e.ChannelID)
+		return err
+	})
+	if err != nil && err.Error() == "method_not_supported_for_channel_type" {
+		return ErrPrivateRejoin
+	}
+	return err
+}
+
+// markRejoined sets RejoinedAt on the latest pending journal entry of each channel
+func (c *Cleaner) markRejoined(rejoined map[string]time.Time) error {
+	entries, err := LoadJournal(c.JournalPath)
+	if err != nil {
+		return err
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		at, ok := rejoined[entries[i].ChannelID]
+		if !ok || entries[i].RejoinedAt != nil {
+			continue
+		}
+		entries[i].RejoinedAt = &at
+		delete(rejoined, entries[i].ChannelID)
+	}
+	return SaveJournal(c.JournalPath, entries)
+}
@@ -0,0 +1,129 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"workspace-channels-cleaner/config"
+	"workspace-channels-cleaner/slack/fakeapi"
+)
+
+func TestLeavesAreJournaledAndCanBeRejoined(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	old := time.Now().AddDate(0, 0, -90)
+	srv.AddChannel(fakeapi.Channel("C1", "public-old", false), fakeapi.Message("U1", old))
+	srv.AddChannel(fakeapi.Channel("C2", "private-old", true), fakeapi.Message("U1", old))
+
+	c := newTestCleaner(t, srv)
+	c.Include = []string{"old"}
+	report := c.LeaveChannels([]ChannelInfo{
+		{ID: "C1", Name: "public-old", Type: "public"},
+		{ID: "C2", Name: "private-old", Type: "private"},
+	})
+	if len(report.Succeeded) != 2 || report.JournalErr != nil {
+		t.Fatalf("leave report %+v, want both channels left and journaled", report)
+	}
+
+	entries, err := LoadJournal(c.JournalPath)
+	if err != nil {
+		t.Fatalf("LoadJournal: %v", err)
+	}
+	if len(entries) != 2 || entries[0].ChannelID != "C1" || entries[1].Type != "private" {
+		t.Fatalf("journal is %+v, want C1 and the private C2", entries)
+	}
+	if sel := entries[0].Selection; sel.Days != 30 || len(sel.Include) != 1 || sel.Include[0] != "old" {
+		t.Errorf("journal selection is %+v, want the scan settings", sel)
+	}
+
+	results := c.RejoinChannels(PendingJournalEntries(entries))
+	if len(results) != 2 || results[0].Err != nil || !errors.Is(results[1].Err, ErrPrivateRejoin) {
+		t.Fatalf("rejoin results %+v, want C1 rejoined and C2 refused as private", results)
+	}
+	if !srv.IsMember("C1") || srv.IsMember("C2") {
+		t.Error("rejoining didn't restore exactly the public channel")
+	}
+
+	entries, err = LoadJournal(c.JournalPath)
+	if err != nil {
+		t.Fatalf("LoadJournal: %v", err)
+	}
+	pending := PendingJournalEntries(entries)
+	if len(pending) != 1 || pending[0].ChannelID != "C2" {
+		t.Errorf("pending entries are %+v, want only C2", pending)
+	}
+}
+
+func TestRejoinReportsChannelsThatTurnedPrivate(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	ch := fakeapi.Channel("C1", "went-private", true)
+	ch.IsMember = false
+	srv.AddChannel(ch)
+
+	c := newTestCleaner(t, srv)
+	results := c.RejoinChannels([]JournalEntry{{ChannelID: "C1", Name: "went-private", Type: "public"}})
+	if len(results) != 1 || !errors.Is(results[0].Err, ErrPrivateRejoin) {
+		t.Errorf("rejoin results %+v, want ErrPrivateRejoin", results)
+	}
+}
+
+func TestJournalRecordsTheRuleThatSelectedAChannel(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	srv.AddChannel(fakeapi.Channel("C1", "inc-42", false), fakeapi.Message("U1", time.Now().AddDate(0, 0, -10)))
+
+	c := newTestCleaner(t, srv)
+	c.Rules = []config.RuleConfig{{Name: "incidents", Pattern: "inc-*", Days: 7}}
+	channels, err := c.GetFilteredChannels(context.Background())
+	if err != nil {
+		t.Fatalf("GetFilteredChannels: %v", err)
+	}
+	if report := c.LeaveChannels(channels); len(report.Succeeded) != 1 || report.JournalErr != nil {
+		t.Fatalf("leave report %+v, want inc-42 left and journaled", report)
+	}
+
+	entries, err := LoadJournal(c.JournalPath)
+	if err != nil {
+		t.Fatalf("LoadJournal: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("journal is %+v, want one entry", entries)
+	}
+	sel := entries[0].Selection
+	if r := sel.Rule; r == nil || r.Name != "incidents" || r.Days != 7 || r.Action != ActionLeave {
+		t.Errorf("journaled rule is %v, want incidents (7d, leave)", r)
+	}
+	if want := c.Cutoff.AddDate(0, 0, 30-7).UTC(); sel.Days != 7 || !sel.Cutoff.Equal(want) {
+		t.Errorf("journaled %d days and cutoff %v, want 7 days and %v", sel.Days, sel.Cutoff, want)
+	}
+}
+
+func TestJournalRecordsThePolicyThatSelectedAChannel(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	srv.AddChannel(fakeapi.Channel("C1", "small-old", false), fakeapi.Message("U1", daysAgo(60)))
+
+	c := newTestCleaner(t, srv)
+	c.Policy = "days_idle > 45"
+	channels, err := c.GetFilteredChannels(context.Background())
+	if err != nil {
+		t.Fatalf("GetFilteredChannels: %v", err)
+	}
+	if report := c.LeaveChannels(channels); len(report.Succeeded) != 1 || report.JournalErr != nil {
+		t.Fatalf("leave report %+v, want small-old left and journaled", report)
+	}
+
+	entries, err := LoadJournal(c.JournalPath)
+	if err != nil {
+		t.Fatalf("LoadJournal: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("journal is %+v, want one entry", entries)
+	}
+	if sel := entries[0].Selection; sel.Policy != "days_idle > 45" || sel.Staleness != config.StalenessChannel {
+		t.Errorf("journal selection is %+v, want the policy and channel staleness", sel)
+	}
+}
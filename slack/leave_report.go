@@ -0,0 +1,33 @@
+package slack
+
+// LeaveFailure is a channel that could not be left
+type LeaveFailure struct {
+	Channel ChannelInfo
+	Err     error
+}
+
+// LeaveSkip is a channel that was not left on purpose
+type LeaveSkip struct {
+	Channel ChannelInfo
+	Reason  string
+}
+
+// LeaveReport lists the outcome of LeaveChannels or ArchiveChannels for every channel
+type LeaveReport struct {
+	Action    Action // What was done to the Succeeded channels
+	Succeeded []ChannelInfo
+	Failed    []LeaveFailure
+	Skipped   []LeaveSkip
+
+	// Muted lists the channels LeaveChannels muted instead because their rule says so
+	Muted []ChannelInfo
+
+	// JournalErr is set when a channel was left or notified but could not be recorded
+	// in the journal or the notices file
+	JournalErr error
+}
+
+// Total returns the number of channels covered by the report
+func (r *LeaveReport) Total() int {
+	return len(r.Succeeded) + len(r.Failed) + len(r.Skipped) + len(r.Muted)
+}
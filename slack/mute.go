@@ -0,0 +1,158 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// DefaultMutedPath is where channels muted by the cleaner are recorded
+const DefaultMutedPath = "config/muted.json"
+
+// MutedEntry records a channel muted by the cleaner, so later scans can offer a
+// final leave once it has stayed stale
+type MutedEntry struct {
+	ChannelID string    `json:"channel_id"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	MutedAt   time.Time `json:"muted_at"`
+}
+
+// LoadMuted loads the muted channels from a JSON file
+func LoadMuted(path string) ([]MutedEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []MutedEntry{}, nil // Return no entries if file doesn't exist
+	}
+
+	var entries []MutedEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse muted channels: %w", err)
+	}
+	return entries, nil
+}
+
+// SaveMuted saves the muted channels to a JSON file
+func SaveMuted(path string, entries []MutedEntry) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal muted channels: %w", err)
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
+// MuteChannels mutes every given channel in the user's notification prefs and
+// records it, keeping the membership. Skip-listed channels and channels whose
+// activity the scan couldn't determine are left alone.
+func (c *Cleaner) MuteChannels(channels []ChannelInfo) *LeaveReport {
+	if err := c.SkipListErr(); err != nil {
+		return failAll(ActionMute, channels, err)
+	}
+	report := &LeaveReport{Action: ActionMute}
+	for i, ch := range channels {
+		if c.Verbose {
+			fmt.Fprintf(c.Out, "➡️  [%d/%d] Muting #%s (ID: %s)...\n", i+1, len(channels), ch.Name, ch.ID)
+		}
+
+		if reason, ok := c.skipReason(ch, false); ok {
+			report.Skipped = append(report.Skipped, LeaveSkip{Channel: ch, Reason: reason})
+			continue
+		}
+
+		// MuteChat reads the prefs before writing them, so pace it as a write
+		err := c.call(context.Background(), "users.prefs.set", func() error {
+			_, err := c.API.MuteChat(ch.ID)
+			return err
+		})
+		if err != nil {
+			if c.Verbose {
+				fmt.Fprintf(c.Out, "❌ Failed to mute #%s: %v\n", ch.Name, err)
+			}
+			report.Failed = append(report.Failed, LeaveFailure{Channel: ch, Err: err})
+			continue
+		}
+
+		report.Succeeded = append(report.Succeeded, ch)
+		if c.Verbose {
+			fmt.Fprintf(c.Out, "🔇 Muted #%s\n", ch.Name)
+		}
+	}
+
+	if len(report.Succeeded) > 0 && c.MutedPath != "" {
+		if err := c.recordMuted(report.Succeeded); err != nil {
+			report.JournalErr = fmt.Errorf("muted %d channel(s) but failed to record them: %w", len(report.Succeeded), err)
+		}
+	}
+	return report
+}
+
+// recordMuted adds the channels to the muted file; a channel muted again keeps one entry
+func (c *Cleaner) recordMuted(channels []ChannelInfo) error {
+	entries, err := LoadMuted(c.MutedPath)
+	if err != nil {
+		return err
+	}
+	index := make(map[string]int, len(entries))
+	for i, e := range entries {
+		index[e.ChannelID] = i
+	}
+	now := time.Now().UTC()
+	for _, ch := range channels {
+		entry := MutedEntry{ChannelID: ch.ID, Name: ch.Name, Type: ch.Type, MutedAt: now}
+		if i, ok := index[ch.ID]; ok {
+			entries[i] = entry
+			continue
+		}
+		index[ch.ID] = len(entries)
+		entries = append(entries, entry)
+	}
+	return SaveMuted(c.MutedPath, entries)
+}
+
+// forgetMuted drops left channels from the muted file
+func (c *Cleaner) forgetMuted(channels []ChannelInfo) error {
+	if c.MutedPath == "" || len(channels) == 0 {
+		return nil
+	}
+	entries, err := LoadMuted(c.MutedPath)
+	if err != nil {
+		return err
+	}
+	left := make(map[string]bool, len(channels))
+	for _, ch := range channels {
+		left[ch.ID] = true
+	}
+	kept := entries[:0]
+	for _, e := range entries {
+		if !left[e.ChannelID] {
+			kept = append(kept, e)
+		}
+	}
+	if len(kept) == len(entries) {
+		return nil
+	}
+	return SaveMuted(c.MutedPath, kept)
+}
+
+// loadMuted reads the muted channels for a scan; an unreadable file only loses the
+// muted markers
+func (c *Cleaner) loadMuted() {
+	c.muted = nil
+	if c.MutedPath == "" {
+		return
+	}
+	entries, err := LoadMuted(c.MutedPath)
+	if err != nil {
+		if c.Verbose {
+			fmt.Fprintf(c.Out, "⚠️  Ignoring muted channels: %v\n", err)
+		}
+		return
+	}
+	c.muted = make(map[string]bool, len(entries))
+	for _, e := range entries {
+		c.muted[e.ChannelID] = true
+	}
+}
@@ -0,0 +1,49 @@
+package slack
+
+import (
+	"context"
+	"testing"
+
+	"workspace-channels-cleaner/slack/fakeapi"
+)
+
+func TestMutedChannelsAreMarkedUntilLeft(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	addStaleChannels(srv, 3)
+
+	c := newTestCleaner(t, srv)
+	report := c.MuteChannels([]ChannelInfo{
+		{ID: "C000", Name: "stale-0", Type: "public"},
+		{ID: "C001", Name: "stale-1", Type: "public"},
+	})
+	if len(report.Succeeded) != 2 || report.JournalErr != nil {
+		t.Fatalf("muted %d channels (journal error %v), want 2", len(report.Succeeded), report.JournalErr)
+	}
+	if !srv.IsMuted("C000") || !srv.IsMuted("C001") || srv.IsMuted("C002") {
+		t.Error("prefs don't mute exactly stale-0 and stale-1")
+	}
+	if !srv.IsMember("C000") {
+		t.Error("muting left the channel")
+	}
+
+	channels, err := c.GetFilteredChannels(context.Background())
+	if err != nil {
+		t.Fatalf("GetFilteredChannels: %v", err)
+	}
+	muted := 0
+	for _, ch := range channels {
+		if ch.Muted {
+			muted++
+		}
+	}
+	if muted != 2 {
+		t.Errorf("scan marked %d channels muted, want 2", muted)
+	}
+
+	c.LeaveChannels([]ChannelInfo{{ID: "C000", Name: "stale-0", Type: "public"}})
+	entries, err := LoadMuted(c.MutedPath)
+	if err != nil || len(entries) != 1 || entries[0].ChannelID != "C001" {
+		t.Errorf("muted entries after leaving stale-0: %+v (%v), want only stale-1", entries, err)
+	}
+}
@@ -0,0 +1,78 @@
+package slack
+
+import (
+	"fmt"
+
+	"workspace-channels-cleaner/config"
+)
+
+// NameFilter selects channels with include and exclude lists of patterns parsed by
+// ParseNamePattern: the skip list's patterns, ignoring case, with plain names
+// matching as substrings.
+//
+// A channel passes when it matches an include entry, or there are none, and
+// matches no exclude entry. Include entries must not start with "-"; see
+// config.ValidateIncludeEntry.
+type NameFilter struct {
+	include []*Pattern
+	exclude []*Pattern
+}
+
+// NewNameFilter parses include and exclude entries
+func NewNameFilter(include, exclude []string) (*NameFilter, error) {
+	f := &NameFilter{}
+	for _, list := range []struct {
+		entries []string
+		into    *[]*Pattern
+		what    string
+	}{
+		{include, &f.include, "include"},
+		{exclude, &f.exclude, "exclude"},
+	} {
+		for _, entry := range list.entries {
+			if list.into == &f.include {
+				if err := config.ValidateIncludeEntry(entry); err != nil {
+					return nil, err
+				}
+			}
+			p, err := ParseNamePattern(entry)
+			if err != nil {
+				return nil, fmt.Errorf("%s entry: %w", list.what, err)
+			}
+			*list.into = append(*list.into, p)
+		}
+	}
+	return f, nil
+}
+
+// Allows reports whether the channel with the given ID and name passes the filter.
+// A nil filter allows every channel.
+func (f *NameFilter) Allows(id, name string) bool {
+	if f == nil {
+		return true
+	}
+	for _, p := range f.exclude {
+		if p.Match(id, name) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, p := range f.include {
+		if p.Match(id, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseNames prepares Include and Exclude for a scan
+func (c *Cleaner) parseNames() error {
+	names, err := NewNameFilter(c.Include, c.Exclude)
+	if err != nil {
+		return fmt.Errorf("invalid name filter: %w", err)
+	}
+	c.names = names
+	return nil
+}
@@ -0,0 +1,69 @@
+package slack
+
+import "testing"
+
+func TestNameFilter(t *testing.T) {
+	f, err := NewNameFilter([]string{"proj-*-archive", "re:^INC-\\d+$", "Standup", "id:C9"}, []string{"-social"})
+	if err != nil {
+		t.Fatalf("NewNameFilter: %v", err)
+	}
+	tests := []struct {
+		id, name string
+		want     bool
+	}{
+		{"C1", "proj-apollo-archive", true},
+		{"C1", "Proj-Apollo-Archive", true},
+		{"C1", "proj-apollo", false},
+		{"C1", "inc-42", true},
+		{"C1", "inc-42-followup", false},
+		{"C1", "team-standup", true},
+		{"C1", "standup-social", false},
+		{"C9", "renamed", true},
+		{"C1", "random", false},
+	}
+	for _, tt := range tests {
+		if got := f.Allows(tt.id, tt.name); got != tt.want {
+			t.Errorf("Allows(%q, %q) = %t, want %t", tt.id, tt.name, got, tt.want)
+		}
+	}
+
+	excludeOnly, err := NewNameFilter(nil, []string{"-social"})
+	if err != nil {
+		t.Fatalf("NewNameFilter: %v", err)
+	}
+	if !excludeOnly.Allows("C1", "random") || excludeOnly.Allows("C1", "team-SOCIAL") {
+		t.Error("an exclude-only filter doesn't allow everything but the excluded names")
+	}
+
+	for _, bad := range []string{"re:inc-(", "team-[", "id:", " ", "-social"} {
+		if _, err := NewNameFilter([]string{bad}, nil); err == nil {
+			t.Errorf("invalid entry %q was accepted", bad)
+		}
+	}
+}
+
+func TestNamePatternsShareTheSkipListGrammar(t *testing.T) {
+	// Apart from case and substrings, an entry matches the same channels in both places
+	for _, raw := range []string{"team-*", "#team-*", `re:^inc-\d+$`, "id:C0123", "general"} {
+		strict, err := ParsePattern(raw)
+		if err != nil {
+			t.Fatalf("ParsePattern(%q): %v", raw, err)
+		}
+		loose, err := ParseNamePattern(raw)
+		if err != nil {
+			t.Fatalf("ParseNamePattern(%q): %v", raw, err)
+		}
+		for _, ch := range []struct{ id, name string }{
+			{"C1", "team-design"}, {"C1", "inc-42"}, {"C0123", "renamed"}, {"C1", "general"}, {"C1", "random"},
+		} {
+			if strict.Match(ch.id, ch.name) && !loose.Match(ch.id, ch.name) {
+				t.Errorf("%q matches #%s in the skip list but not as a name filter", raw, ch.name)
+			}
+		}
+	}
+
+	loose, _ := ParseNamePattern("general")
+	if !loose.Match("C1", "General-Chat") {
+		t.Error("a plain name filter entry doesn't match as a substring ignoring case")
+	}
+}
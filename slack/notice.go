@@ -0,0 +1,301 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// DefaultNoticesPath is where posted pre-archive notices are recorded
+const DefaultNoticesPath = "config/notices.json"
+
+// Notice records a pre-archive notice posted to a channel
+type Notice struct {
+	ChannelID    string    `json:"channel_id"`
+	Name         string    `json:"name"`
+	Type         string    `json:"type"`
+	TS           string    `json:"ts"` // Timestamp of the notice message
+	PostedAt     time.Time `json:"posted_at"`
+	ArchiveAfter time.Time `json:"archive_after"` // End of the grace period
+	IsGeneral    bool      `json:"is_general,omitempty"`
+}
+
+// NoticeData is what the notice message template is rendered with
+type NoticeData struct {
+	Channel     string // Channel name without the #
+	ID          string
+	Days        int    // Days since the channel's last activity
+	GraceDays   int    // Days members have to object
+	ArchiveDate string // When the channel may be archived, as 2006-01-02
+}
+
+// NoticeStatus is where a notice stands after its channel was re-checked
+type NoticeStatus int
+
+const (
+	NoticeWaiting  NoticeStatus = iota // The grace period hasn't ended yet
+	NoticeObjected                     // Someone posted or replied since the notice
+	NoticeReady                        // The grace period is over without new human activity
+)
+
+// NoticeReview is the outcome of re-checking one notice
+type NoticeReview struct {
+	Notice Notice
+	Status NoticeStatus
+	Err    error // The channel could not be checked; the notice is kept
+}
+
+// LoadNotices loads the posted notices from a JSON file
+func LoadNotices(path string) ([]Notice, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []Notice{}, nil // Return no notices if file doesn't exist
+	}
+
+	var notices []Notice
+	if err := json.Unmarshal(data, &notices); err != nil {
+		return nil, fmt.Errorf("failed to parse notices: %w", err)
+	}
+	return notices, nil
+}
+
+// SaveNotices saves the posted notices to a JSON file
+func SaveNotices(path string, notices []Notice) error {
+	data, err := json.MarshalIndent(notices, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal notices: %w", err)
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
+// PostNotices posts the notice message to every given channel and records it, so
+// a later ResolveNotices can archive the channels nobody objected for. Channels
+// that already have a pending notice, or whose activity could not be determined,
+// are skipped.
+func (c *Cleaner) PostNotices(channels []ChannelInfo) *LeaveReport {
+	if err := c.SkipListErr(); err != nil {
+		return failAll(ActionNotice, channels, err)
+	}
+	report := &LeaveReport{Action: ActionNotice}
+
+	tmpl, err := template.New("notice").Parse(c.NoticeMessage)
+	if err != nil {
+		for _, ch := range channels {
+			report.Failed = append(report.Failed, LeaveFailure{Channel: ch, Err: fmt.Errorf("invalid notice message: %w", err)})
+		}
+		return report
+	}
+	notices, err := LoadNotices(c.NoticesPath)
+	if err != nil {
+		for _, ch := range channels {
+			report.Failed = append(report.Failed, LeaveFailure{Channel: ch, Err: err})
+		}
+		return report
+	}
+	pending := make(map[string]Notice, len(notices))
+	for _, n := range notices {
+		pending[n.ChannelID] = n
+	}
+
+	for i, ch := range channels {
+		if c.Verbose {
+			fmt.Fprintf(c.Out, "➡️  [%d/%d] Posting notice to #%s (ID: %s)...\n", i+1, len(channels), ch.Name, ch.ID)
+		}
+
+		if reason, ok := c.skipReason(ch, true); ok {
+			report.Skipped = append(report.Skipped, LeaveSkip{Channel: ch, Reason: reason})
+			continue
+		}
+		if n, ok := pending[ch.ID]; ok {
+			report.Skipped = append(report.Skipped, LeaveSkip{Channel: ch, Reason: fmt.Sprintf("notice already posted on %s", n.PostedAt.Local().Format("2006-01-02"))})
+			continue
+		}
+
+		now := time.Now().UTC()
+		archiveAfter := now.AddDate(0, 0, c.GraceDays)
+		var text strings.Builder
+		if err := tmpl.Execute(&text, c.noticeData(ch, now, archiveAfter)); err != nil {
+			report.Failed = append(report.Failed, LeaveFailure{Channel: ch, Err: fmt.Errorf("failed to render notice: %w", err)})
+			continue
+		}
+
+		var ts string
+		err := c.call(context.Background(), "chat.postMessage", func() (err error) {
+			_, ts, err = c.API.PostMessageContext(context.Background(), ch.ID, slack.MsgOptionText(text.String(), false))
+			return err
+		})
+		if err != nil {
+			if c.Verbose {
+				fmt.Fprintf(c.Out, "❌ Failed to post notice to #%s: %v\n", ch.Name, err)
+			}
+			report.Failed = append(report.Failed, LeaveFailure{Channel: ch, Err: err})
+			continue
+		}
+
+		report.Succeeded = append(report.Succeeded, ch)
+		notices = append(notices, Notice{
+			ChannelID:    ch.ID,
+			Name:         ch.Name,
+			Type:         ch.Type,
+			TS:           ts,
+			PostedAt:     now,
+			ArchiveAfter: archiveAfter,
+			IsGeneral:    ch.IsGeneral,
+		})
+		if c.Verbose {
+			fmt.Fprintf(c.Out, "✅ Posted notice to #%s\n", ch.Name)
+		}
+	}
+
+	if len(report.Succeeded) > 0 && c.NoticesPath != "" {
+		if err := SaveNotices(c.NoticesPath, notices); err != nil {
+			report.JournalErr = fmt.Errorf("posted %d notice(s) but failed to record them: %w", len(report.Succeeded), err)
+		}
+	}
+	return report
+}
+
+func (c *Cleaner) noticeData(ch ChannelInfo, now, archiveAfter time.Time) NoticeData {
+	days := c.Days
+	if !ch.LastSeen.IsZero() {
+		days = int(now.Sub(ch.LastSeen).Hours() / 24)
+	}
+	return NoticeData{
+		Channel:     ch.Name,
+		ID:          ch.ID,
+		Days:        days,
+		GraceDays:   c.GraceDays,
+		ArchiveDate: archiveAfter.Local().Format("2006-01-02"),
+	}
+}
+
+// ReviewNotices re-checks the channel of every notice whose grace period is over
+func (c *Cleaner) ReviewNotices(ctx context.Context, notices []Notice) []NoticeReview {
+	reviews := make([]NoticeReview, 0, len(notices))
+	for _, n := range notices {
+		if time.Now().Before(n.ArchiveAfter) {
+			reviews = append(reviews, NoticeReview{Notice: n, Status: NoticeWaiting})
+			continue
+		}
+		objected, err := c.activitySinceNotice(ctx, n)
+		status := NoticeReady
+		if objected {
+			status = NoticeObjected
+		}
+		reviews = append(reviews, NoticeReview{Notice: n, Status: status, Err: err})
+	}
+	return reviews
+}
+
+// activitySinceNotice reports whether anyone posted in the channel or replied to the
+// notice since it was posted. The notice itself never counts, and replies are
+// filtered like messages, so a bot answering the notice is no objection.
+func (c *Cleaner) activitySinceNotice(ctx context.Context, n Notice) (bool, error) {
+	cursor := ""
+	for {
+		var history *slack.GetConversationHistoryResponse
+		err := c.call(ctx, "conversations.history", func() (err error) {
+			history, err = c.API.GetConversationHistoryContext(ctx, &slack.GetConversationHistoryParameters{
+				ChannelID: n.ChannelID,
+				Cursor:    cursor,
+				Oldest:    n.TS,
+				Inclusive: true,
+				Limit:     historyPageSize,
+			})
+			return err
+		})
+		if err != nil {
+			return false, err
+		}
+
+		for _, msg := range history.Messages {
+			if msg.Timestamp == n.TS {
+				if msg.LatestReply == "" || !c.Activity.CountsReplies(msg) {
+					continue
+				}
+				replyTS, err := c.latestReply(ctx, n.ChannelID, msg, c.Activity.Counts)
+				if err != nil {
+					return false, err
+				}
+				if !replyTS.IsZero() {
+					return true, nil
+				}
+				continue
+			}
+			if c.Activity.Counts(msg) {
+				return true, nil
+			}
+		}
+
+		cursor = history.ResponseMetaData.NextCursor
+		if !history.HasMore || cursor == "" {
+			return false, nil
+		}
+	}
+}
+
+// ResolveNotices archives the channels of ready notices and forgets the notices that
+// are settled: archived channels, channels someone objected in, and channels that
+// can't be archived. Waiting notices and failed channels stay for the next run.
+func (c *Cleaner) ResolveNotices(reviews []NoticeReview) *LeaveReport {
+	var ready []ChannelInfo
+	settled := make(map[string]bool)
+	var objected []LeaveSkip
+	for _, r := range reviews {
+		if r.Err != nil {
+			continue
+		}
+		ch := ChannelInfo{ID: r.Notice.ChannelID, Name: r.Notice.Name, Type: r.Notice.Type, IsGeneral: r.Notice.IsGeneral}
+		switch r.Status {
+		case NoticeReady:
+			ready = append(ready, ch)
+		case NoticeObjected:
+			settled[ch.ID] = true
+			objected = append(objected, LeaveSkip{Channel: ch, Reason: fmt.Sprintf("active since the notice on %s", r.Notice.PostedAt.Local().Format("2006-01-02"))})
+		}
+	}
+
+	report := c.ArchiveChannels(ready)
+	for _, ch := range report.Succeeded {
+		settled[ch.ID] = true
+	}
+	for _, sk := range report.Skipped {
+		settled[sk.Channel.ID] = true
+	}
+	for _, r := range reviews {
+		if r.Err != nil {
+			ch := ChannelInfo{ID: r.Notice.ChannelID, Name: r.Notice.Name, Type: r.Notice.Type}
+			report.Failed = append(report.Failed, LeaveFailure{Channel: ch, Err: fmt.Errorf("failed to re-check: %w", r.Err)})
+		}
+	}
+	report.Skipped = append(report.Skipped, objected...)
+
+	if len(settled) > 0 && c.NoticesPath != "" {
+		if err := c.forgetNotices(settled); err != nil {
+			report.JournalErr = fmt.Errorf("failed to update notices: %w", err)
+		}
+	}
+	return report
+}
+
+// forgetNotices removes the notices of the given channels from the notices file
+func (c *Cleaner) forgetNotices(channelIDs map[string]bool) error {
+	notices, err := LoadNotices(c.NoticesPath)
+	if err != nil {
+		return err
+	}
+	kept := notices[:0]
+	for _, n := range notices {
+		if !channelIDs[n.ChannelID] {
+			kept = append(kept, n)
+		}
+	}
+	return SaveNotices(c.NoticesPath, kept)
+}
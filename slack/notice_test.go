@@ -0,0 +1,135 @@
+package slack
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"workspace-channels-cleaner/config"
+	"workspace-channels-cleaner/slack/fakeapi"
+)
+
+func TestNoticeThenArchiveOnlyUncontestedChannels(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	addStaleChannels(srv, 4)
+
+	c := newTestCleaner(t, srv)
+	c.NoticeMessage = "#{{.Channel}} goes on {{.ArchiveDate}}"
+	c.Activity = NewActivityFilter(config.DefaultIgnoredSubtypes, nil)
+	channels := []ChannelInfo{
+		{ID: "C000", Name: "stale-0", Type: "public"},
+		{ID: "C001", Name: "stale-1", Type: "public"},
+		{ID: "C002", Name: "stale-2", Type: "public"},
+		{ID: "C003", Name: "stale-3", Type: "public"},
+	}
+	report := c.PostNotices(channels)
+	if len(report.Succeeded) != 4 || report.JournalErr != nil {
+		t.Fatalf("posted %d notices (journal error %v), want 4", len(report.Succeeded), report.JournalErr)
+	}
+	if text := srv.Messages("C000")[0].Text; !strings.HasPrefix(text, "#stale-0 goes on ") {
+		t.Errorf("notice text is %q, want the rendered template", text)
+	}
+
+	// Posting again must not notify the same channel twice
+	if again := c.PostNotices(channels[:1]); len(again.Skipped) != 1 {
+		t.Errorf("second notice to #stale-0 not skipped: %+v", again)
+	}
+
+	notices, err := LoadNotices(c.NoticesPath)
+	if err != nil || len(notices) != 4 {
+		t.Fatalf("loaded %d notices (%v), want 4", len(notices), err)
+	}
+	later := time.Now().Add(time.Second)
+	srv.Post("C001", fakeapi.Message("U2", later))
+	srv.AddReplies("C002", notices[2].TS, fakeapi.Message("U3", later))
+	srv.Post("C000", fakeapi.EventMessage("channel_join", "U4", later)) // Not human activity
+	for i := range notices[:3] {
+		notices[i].ArchiveAfter = time.Now().Add(-time.Minute) // Grace period over
+	}
+
+	reviews := c.ReviewNotices(context.Background(), notices)
+	want := []NoticeStatus{NoticeReady, NoticeObjected, NoticeObjected, NoticeWaiting}
+	for i, r := range reviews {
+		if r.Err != nil || r.Status != want[i] {
+			t.Errorf("#%s reviewed as %d (%v), want %d", r.Notice.Name, r.Status, r.Err, want[i])
+		}
+	}
+
+	resolved := c.ResolveNotices(reviews)
+	if len(resolved.Succeeded) != 1 || resolved.Succeeded[0].ID != "C000" || !srv.IsArchived("C000") {
+		t.Errorf("archived %+v, want only stale-0", resolved.Succeeded)
+	}
+	if srv.IsArchived("C001") || srv.IsArchived("C002") {
+		t.Error("archived a channel someone objected in")
+	}
+	if len(resolved.Skipped) != 2 {
+		t.Errorf("got %d skipped, want the 2 objected channels", len(resolved.Skipped))
+	}
+
+	left, err := LoadNotices(c.NoticesPath)
+	if err != nil || len(left) != 1 || left[0].ChannelID != "C003" {
+		t.Errorf("notices after resolving: %+v (%v), want only the waiting stale-3", left, err)
+	}
+}
+
+func TestBotRepliesToANoticeAreNoObjection(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	addStaleChannels(srv, 2)
+
+	c := newTestCleaner(t, srv)
+	c.NoticeMessage = "#{{.Channel}} goes on {{.ArchiveDate}}"
+	c.Activity = NewActivityFilter(config.DefaultIgnoredSubtypes, []string{"UREMIND"})
+	report := c.PostNotices([]ChannelInfo{{ID: "C000", Name: "stale-0", Type: "public"}, {ID: "C001", Name: "stale-1", Type: "public"}})
+	if len(report.Succeeded) != 2 {
+		t.Fatalf("posted %d notices, want 2", len(report.Succeeded))
+	}
+
+	notices, err := LoadNotices(c.NoticesPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Second)
+	srv.AddReplies("C000", notices[0].TS, fakeapi.BotMessage("B1", later), fakeapi.Message("UREMIND", later.Add(time.Second)))
+	srv.AddReplies("C001", notices[1].TS, fakeapi.Message("U2", later), fakeapi.BotMessage("B1", later.Add(time.Second)))
+	for i := range notices {
+		notices[i].ArchiveAfter = time.Now().Add(-time.Minute)
+	}
+
+	reviews := c.ReviewNotices(context.Background(), notices)
+	want := []NoticeStatus{NoticeReady, NoticeObjected}
+	for i, r := range reviews {
+		if r.Err != nil || r.Status != want[i] {
+			t.Errorf("#%s reviewed as %d (%v), want %d", r.Notice.Name, r.Status, r.Err, want[i])
+		}
+	}
+}
+
+func TestNoticesSkipUndeterminedChannels(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	addStaleChannels(srv, 2)
+
+	c := newTestCleaner(t, srv)
+	c.NoticeMessage = "#{{.Channel}} goes on {{.ArchiveDate}}"
+	report := c.PostNotices([]ChannelInfo{
+		{ID: "C000", Name: "stale-0", Type: "public"},
+		{ID: "C001", Name: "stale-1", Type: "public", Error: "internal_error"},
+	})
+
+	if len(report.Succeeded) != 1 || report.Succeeded[0].ID != "C000" {
+		t.Errorf("notified %+v, want only stale-0", report.Succeeded)
+	}
+	if len(report.Skipped) != 1 || report.Skipped[0].Channel.ID != "C001" {
+		t.Fatalf("skipped %+v, want the undetermined stale-1", report.Skipped)
+	}
+	if calls := srv.Calls("chat.postMessage"); calls != 1 {
+		t.Errorf("posted %d notices, want 1", calls)
+	}
+	notices, err := LoadNotices(c.NoticesPath)
+	if err != nil || len(notices) != 1 || notices[0].ChannelID != "C000" {
+		t.Errorf("recorded notices %+v (%v), want only stale-0", notices, err)
+	}
+}
@@ -0,0 +1,351 @@
+package slack
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/slack-go/slack"
+
+	"workspace-channels-cleaner/config"
+)
+
+// ErrOffline is returned by the calls a workspace export can't answer, such as leaving a channel
+var ErrOffline = errors.New("not available when analyzing a workspace export")
+
+// PosterCount is how many messages a user posted in a channel
+type PosterCount struct {
+	User     string `json:"user"`
+	Name     string `json:"name,omitempty"`
+	Messages int    `json:"messages"`
+}
+
+// ExportArchive is a standard workspace export: channels.json, groups.json and a
+// directory of day files per channel. It answers the read-only calls of
+// WorkspaceAPI from the export, so a cleaner can scan it without a token.
+type ExportArchive struct {
+	channels []slack.Channel
+	history  map[string][]slack.Message // Top-level messages by channel ID, newest first
+	replies  map[string][]slack.Message // Thread replies by channel ID and parent ts, oldest first
+	users    map[string]string          // User ID to display name, from users.json when present
+}
+
+var _ WorkspaceAPI = (*ExportArchive)(nil)
+
+// OpenExport reads a workspace export ZIP, or a directory it was unpacked to
+func OpenExport(name string) (*ExportArchive, error) {
+	info, err := os.Stat(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open export: %w", err)
+	}
+	if info.IsDir() {
+		return LoadExport(os.DirFS(name))
+	}
+
+	zr, err := zip.OpenReader(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open export: %w", err)
+	}
+	defer zr.Close()
+	return LoadExport(zr)
+}
+
+// LoadExport reads a workspace export from a file system laid out like the export ZIP
+func LoadExport(fsys fs.FS) (*ExportArchive, error) {
+	e := &ExportArchive{
+		history: make(map[string][]slack.Message),
+		replies: make(map[string][]slack.Message),
+		users:   make(map[string]string),
+	}
+
+	found := false
+	for _, list := range []struct {
+		file    string
+		private bool
+	}{{"channels.json", false}, {"groups.json", true}} {
+		var channels []slack.Channel
+		err := readJSON(fsys, list.file, &channels)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		found = true
+		for _, ch := range channels {
+			// The export is a view of the whole workspace, so every channel is in scope
+			ch.IsChannel = true
+			ch.IsPrivate = list.private
+			ch.IsMember = true
+			ch.NumMembers = len(ch.Members)
+			if err := e.loadDays(fsys, ch); err != nil {
+				return nil, err
+			}
+			e.channels = append(e.channels, ch)
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("not a workspace export: neither channels.json nor groups.json found")
+	}
+
+	var users []slack.User
+	if err := readJSON(fsys, "users.json", &users); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	for _, u := range users {
+		name := u.Profile.DisplayName
+		if name == "" {
+			name = u.Name
+		}
+		e.users[u.ID] = name
+	}
+	return e, nil
+}
+
+// loadDays reads the day files of a channel and splits thread replies from the
+// top-level messages, as conversations.history and conversations.replies would
+func (e *ExportArchive) loadDays(fsys fs.FS, ch slack.Channel) error {
+	days, err := fs.Glob(fsys, path.Join(ch.Name, "*.json"))
+	if err != nil {
+		return err
+	}
+	var top []slack.Message
+	for _, day := range days {
+		var msgs []slack.Message
+		if err := readJSON(fsys, day, &msgs); err != nil {
+			return err
+		}
+		for _, msg := range msgs {
+			if msg.ThreadTimestamp != "" && msg.ThreadTimestamp != msg.Timestamp {
+				key := ch.ID + "/" + msg.ThreadTimestamp
+				e.replies[key] = append(e.replies[key], msg)
+				if msg.SubType != "thread_broadcast" {
+					continue
+				}
+			}
+			top = append(top, msg)
+		}
+	}
+
+	sort.SliceStable(top, func(i, j int) bool { return tsValue(top[i].Timestamp) > tsValue(top[j].Timestamp) })
+	for i, msg := range top {
+		replies := e.replies[ch.ID+"/"+msg.Timestamp]
+		if len(replies) == 0 {
+			continue
+		}
+		sort.SliceStable(replies, func(i, j int) bool { return tsValue(replies[i].Timestamp) < tsValue(replies[j].Timestamp) })
+		// Older exports leave out the thread summary of the parent
+		if msg.LatestReply == "" {
+			users := make([]string, 0, len(replies))
+			for _, r := range replies {
+				users = append(users, r.User)
+			}
+			top[i].ThreadTimestamp = msg.Timestamp
+			top[i].ReplyCount = len(replies)
+			top[i].ReplyUsers = users
+			top[i].LatestReply = replies[len(replies)-1].Timestamp
+		}
+	}
+	e.history[ch.ID] = top
+	return nil
+}
+
+func readJSON(fsys fs.FS, name string, v interface{}) error {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	return nil
+}
+
+func tsValue(ts string) float64 {
+	f, _ := strconv.ParseFloat(ts, 64)
+	return f
+}
+
+// offsetCursor pages through in-memory lists the way the API pages with cursors
+func offsetCursor(cursor string, total, limit int) (int, int, string) {
+	start, _ := strconv.Atoi(cursor)
+	if limit <= 0 {
+		limit = historyPageSize
+	}
+	start = min(start, total)
+	end := min(start+limit, total)
+	next := ""
+	if end < total {
+		next = strconv.Itoa(end)
+	}
+	return start, end, next
+}
+
+// GetConversationsContext lists the channels of the export
+func (e *ExportArchive) GetConversationsContext(ctx context.Context, params *slack.GetConversationsParameters) ([]slack.Channel, string, error) {
+	var matching []slack.Channel
+	for _, ch := range e.channels {
+		if params.ExcludeArchived && ch.IsArchived {
+			continue
+		}
+		want := "public_channel"
+		if ch.IsPrivate {
+			want = "private_channel"
+		}
+		if len(params.Types) > 0 && !containsString(params.Types, want) {
+			continue
+		}
+		matching = append(matching, ch)
+	}
+	start, end, next := offsetCursor(params.Cursor, len(matching), params.Limit)
+	return matching[start:end], next, nil
+}
+
+// GetConversationHistoryContext returns a channel's top-level messages, newest first
+func (e *ExportArchive) GetConversationHistoryContext(ctx context.Context, params *slack.GetConversationHistoryParameters) (*slack.GetConversationHistoryResponse, error) {
+	msgs, ok := e.history[params.ChannelID]
+	if !ok {
+		return nil, errors.New("channel_not_found")
+	}
+	oldest, latest := tsValue(params.Oldest), tsValue(params.Latest)
+	var matching []slack.Message
+	for _, msg := range msgs {
+		ts := tsValue(msg.Timestamp)
+		if oldest > 0 && (ts < oldest || (ts == oldest && !params.Inclusive)) {
+			continue
+		}
+		if latest > 0 && (ts > latest || (ts == latest && !params.Inclusive)) {
+			continue
+		}
+		matching = append(matching, msg)
+	}
+
+	start, end, next := offsetCursor(params.Cursor, len(matching), params.Limit)
+	resp := &slack.GetConversationHistoryResponse{HasMore: next != "", Messages: matching[start:end]}
+	resp.Ok = true
+	resp.ResponseMetaData.NextCursor = next
+	return resp, nil
+}
+
+// GetConversationRepliesContext returns a thread's parent followed by its replies
+func (e *ExportArchive) GetConversationRepliesContext(ctx context.Context, params *slack.GetConversationRepliesParameters) ([]slack.Message, bool, string, error) {
+	var parent *slack.Message
+	for i, msg := range e.history[params.ChannelID] {
+		if msg.Timestamp == params.Timestamp {
+			parent = &e.history[params.ChannelID][i]
+			break
+		}
+	}
+	if parent == nil {
+		return nil, false, "", errors.New("thread_not_found")
+	}
+	replies := e.replies[params.ChannelID+"/"+params.Timestamp]
+	start, end, next := offsetCursor(params.Cursor, len(replies), params.Limit)
+	return append([]slack.Message{*parent}, replies[start:end]...), next != "", next, nil
+}
+
+// AuthTestContext fails: an export has no authenticated user. Set Cleaner.UserID
+// to judge staleness by one user's own activity.
+func (e *ExportArchive) AuthTestContext(ctx context.Context) (*slack.AuthTestResponse, error) {
+	return nil, ErrOffline
+}
+
+func (e *ExportArchive) LeaveConversation(channelID string) (bool, error) {
+	return false, ErrOffline
+}
+
+func (e *ExportArchive) JoinConversation(channelID string) (*slack.Channel, string, []string, error) {
+	return nil, "", nil, ErrOffline
+}
+
+func (e *ExportArchive) ArchiveConversation(channelID string) error {
+	return ErrOffline
+}
+
+func (e *ExportArchive) PostMessageContext(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
+	return "", "", ErrOffline
+}
+
+func (e *ExportArchive) MuteChat(channelID string) (*slack.UserPrefsCarrier, error) {
+	return nil, ErrOffline
+}
+
+// TopPosters returns up to n users with the most messages in a channel, thread
+// replies included. Bot posts and system messages such as joins don't count.
+func (e *ExportArchive) TopPosters(channelID string, n int) []PosterCount {
+	if n <= 0 {
+		return nil
+	}
+	counts := make(map[string]int)
+	count := func(msg slack.Message) {
+		if msg.User != "" && msg.BotID == "" && (msg.SubType == "" || msg.SubType == "thread_broadcast") {
+			counts[msg.User]++
+		}
+	}
+	for _, msg := range e.history[channelID] {
+		count(msg)
+	}
+	prefix := channelID + "/"
+	for key, replies := range e.replies {
+		if strings.HasPrefix(key, prefix) {
+			for _, msg := range replies {
+				if msg.SubType != "thread_broadcast" { // Already counted in the history
+					count(msg)
+				}
+			}
+		}
+	}
+
+	posters := make([]PosterCount, 0, len(counts))
+	for user, c := range counts {
+		posters = append(posters, PosterCount{User: user, Name: e.users[user], Messages: c})
+	}
+	sort.Slice(posters, func(i, j int) bool {
+		if posters[i].Messages != posters[j].Messages {
+			return posters[i].Messages > posters[j].Messages
+		}
+		return posters[i].User < posters[j].User
+	})
+	if len(posters) > n {
+		posters = posters[:n]
+	}
+	return posters
+}
+
+// AddTopPosters fills in the TopPosters of scanned channels, up to n per channel
+func (e *ExportArchive) AddTopPosters(channels []ChannelInfo, n int) {
+	for i := range channels {
+		channels[i].TopPosters = e.TopPosters(channels[i].ID, n)
+	}
+}
+
+// NewOfflineCleaner creates a cleaner that scans a workspace export with the given
+// configuration. Calls aren't rate limited and nothing is cached or journaled.
+func NewOfflineCleaner(archive *ExportArchive, appConfig *config.AppConfig) *Cleaner {
+	c := NewCleanerWithAPI(archive, appConfig.Limit, GetChannelTypes(appConfig.Types), appConfig.Days, appConfig.Verbose)
+	c.Configure(appConfig)
+	c.Limiter = nil
+	c.CachePath = ""
+	c.JournalPath = ""
+	c.NoticesPath = ""
+	c.MutedPath = ""
+	c.ExportDir = ""
+	return c
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package slack
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"workspace-channels-cleaner/config"
+	"workspace-channels-cleaner/slack/fakeapi"
+)
+
+// writeExportZip writes a workspace export with the given files, marshalled as JSON
+func writeExportZip(t *testing.T, files map[string]interface{}) string {
+	t.Helper()
+	name := filepath.Join(t.TempDir(), "export.zip")
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for path, content := range files {
+		w, err := zw.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.NewEncoder(w).Encode(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	return name
+}
+
+func exportChannel(id, name string, members ...string) slack.Channel {
+	var ch slack.Channel
+	ch.ID = id
+	ch.Name = name
+	ch.Members = members
+	return ch
+}
+
+func TestAnalyzeWorkspaceExportOffline(t *testing.T) {
+	now := time.Now()
+	old := now.AddDate(0, 0, -90)
+	parent := fakeapi.Message("U1", old)
+	reply := fakeapi.Message("U3", now.AddDate(0, 0, -1))
+	reply.ThreadTimestamp = parent.Timestamp
+	day := func(msgs ...slack.Message) []slack.Message { return msgs }
+
+	name := writeExportZip(t, map[string]interface{}{
+		"channels.json": []slack.Channel{
+			exportChannel("C1", "old-project", "U1", "U2", "U3"),
+			exportChannel("C2", "busy", "U1"),
+		},
+		"groups.json":                 []slack.Channel{exportChannel("G1", "secret", "U1", "U2")},
+		"users.json":                  []slack.User{{ID: "U1", Name: "ada"}},
+		"old-project/2024-01-01.json": day(parent, fakeapi.Message("U1", old.Add(time.Minute)), fakeapi.Message("U2", old.Add(2*time.Minute))),
+		"old-project/2024-01-02.json": day(fakeapi.Message("U1", old.Add(24*time.Hour)), reply),
+		"old-project/2024-01-03.json": day(fakeapi.BotMessage("B1", now), fakeapi.EventMessage("channel_join", "U4", now)),
+		"busy/2024-01-01.json":        day(fakeapi.Message("U1", now)),
+	})
+
+	archive, err := OpenExport(name)
+	if err != nil {
+		t.Fatalf("OpenExport: %v", err)
+	}
+	appConfig := config.DefaultConfig()
+	appConfig.Types = []string{"public", "private"}
+	appConfig.IncludeEmpty = true
+	c := NewOfflineCleaner(archive, appConfig)
+	c.SkipList = nil
+	c.Out = io.Discard
+
+	channels, err := c.GetFilteredChannels(context.Background())
+	if err != nil {
+		t.Fatalf("GetFilteredChannels: %v", err)
+	}
+	archive.AddTopPosters(channels, 2)
+
+	if len(channels) != 2 {
+		t.Fatalf("got %d stale channels, want old-project and the empty secret", len(channels))
+	}
+	byName := make(map[string]ChannelInfo)
+	for _, ch := range channels {
+		byName[ch.Name] = ch
+	}
+	project, ok := byName["old-project"]
+	if !ok || project.NumMembers != 3 || project.Type != "public" {
+		t.Fatalf("old-project is %+v, want a stale public channel with 3 members", project)
+	}
+	if project.LastSeen.Unix() != old.Add(24*time.Hour).Unix() {
+		t.Errorf("old-project last seen %v, want the last human message", project.LastSeen)
+	}
+	posters := project.TopPosters
+	if len(posters) != 2 || posters[0].User != "U1" || posters[0].Name != "ada" || posters[0].Messages != 3 || posters[1].User != "U2" {
+		t.Errorf("top posters %+v, want ada with 3 then U2", posters)
+	}
+	if posters := archive.TopPosters("C1", -1); posters != nil {
+		t.Errorf("top -1 posters %+v, want none", posters)
+	}
+	if secret, ok := byName["secret"]; !ok || secret.Type != "private" || !secret.LastSeen.IsZero() {
+		t.Errorf("secret is %+v, want an empty private channel", secret)
+	}
+
+	if report := c.LeaveChannels(channels); len(report.Failed) != 2 {
+		t.Errorf("leaving from an export failed %d of 2 channels, want all", len(report.Failed))
+	}
+}
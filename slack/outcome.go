@@ -0,0 +1,112 @@
+package slack
+
+import (
+	"context"
+
+	"github.com/slack-go/slack"
+)
+
+// Outcome is the verdict of checking a single channel
+type Outcome int
+
+const (
+	OutcomeStale Outcome = iota
+	OutcomeActive
+	OutcomeError    // The channel's activity could not be determined
+	OutcomeExcluded // The policy doesn't hold for the channel
+)
+
+// ChannelOutcome is what checking a single channel produced
+type ChannelOutcome struct {
+	Channel ChannelInfo
+	Outcome Outcome
+	Cached  bool  // The verdict came from the scan cache
+	Err     error // Why the channel could not be checked, for OutcomeError
+}
+
+// checkOutcome decides whether a listed channel is stale. It always returns an
+// outcome; failures are reported as OutcomeError rather than dropped.
+func (c *Cleaner) checkOutcome(ctx context.Context, ch slack.Channel) ChannelOutcome {
+	o := ChannelOutcome{Channel: ChannelInfo{
+		ID:          ch.ID,
+		Name:        ch.Name,
+		Type:        channelType(ch),
+		Created:     ch.Created.Time(),
+		IsGeneral:   ch.IsGeneral,
+		IsShared:    ch.IsShared,
+		IsExtShared: ch.IsExtShared,
+		NumMembers:  ch.NumMembers,
+		Creator:     ch.Creator,
+		Topic:       ch.Topic.Value,
+		Purpose:     ch.Purpose.Value,
+		Muted:       c.muted[ch.ID],
+	}}
+	o.Channel.Rule = c.matchRule(ch.ID, ch.Name, o.Channel.Type)
+	cutoff := c.historyCutoff(o.Channel.Rule)
+
+	activity, cached := c.cachedActivity(ch.ID, cutoff)
+	if !cached {
+		var err error
+		activity, err = c.CheckChannel(ctx, ch.ID, cutoff)
+		if err != nil {
+			o.Outcome, o.Err = OutcomeError, err
+			o.Channel.Error = err.Error()
+			return o
+		}
+		c.cacheActivity(ch.ID, activity)
+	}
+
+	o.Cached = cached
+	o.Channel.LastSeen = activity.LastSeen
+	o.Channel.ActivitySource = activity.Source
+	o.Channel.MyLastSeen = activity.MyLastSeen
+	o.Outcome = c.judge(o.Channel, activity)
+	return o
+}
+
+// judge decides whether a checked channel is stale. A policy, when set, decides on
+// its own; otherwise the channel's cutoff does. Channels without any activity are
+// only stale with IncludeEmpty either way.
+func (c *Cleaner) judge(ch ChannelInfo, activity ChannelActivity) Outcome {
+	if c.policy == nil {
+		if c.IsStale(activity, c.CutoffFor(ch.Rule)) {
+			return OutcomeStale
+		}
+		return OutcomeActive
+	}
+	if activity.LastSeen.IsZero() && !c.IncludeEmpty {
+		return OutcomeActive
+	}
+	if c.policy.Eval(c.policyChannel(ch)) {
+		return OutcomeStale
+	}
+	return OutcomeExcluded
+}
+
+// RecheckChannel checks a channel from an earlier scan again, such as one in a
+// plan, and reports whether it is still stale. The channel is returned with its
+// activity updated.
+func (c *Cleaner) RecheckChannel(ctx context.Context, ch ChannelInfo) (ChannelInfo, bool, error) {
+	if err := c.parsePolicy(); err != nil {
+		return ch, false, err
+	}
+	if err := c.resolveIdentity(ctx); err != nil {
+		return ch, false, err
+	}
+	activity, err := c.CheckChannel(ctx, ch.ID, c.historyCutoff(ch.Rule))
+	if err != nil {
+		return ch, false, err
+	}
+	ch.LastSeen = activity.LastSeen
+	ch.ActivitySource = activity.Source
+	ch.MyLastSeen = activity.MyLastSeen
+	return ch, c.judge(ch, activity) == OutcomeStale, nil
+}
+
+// channelType returns "public" or "private" for a listed channel
+func channelType(ch slack.Channel) string {
+	if ch.IsPrivate {
+		return "private"
+	}
+	return "public"
+}
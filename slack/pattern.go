@@ -0,0 +1,118 @@
+package slack
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// patternKind identifies how a Pattern matches channels
+type patternKind int
+
+const (
+	patternExact patternKind = iota
+	patternGlob
+	patternRegex
+	patternID
+	patternSubstring
+)
+
+// Pattern matches channels by exact name, glob, regular expression or ID:
+//
+//	general        exact channel name
+//	team-*         glob on the channel name (*, ? and [...])
+//	re:^inc-\d+$   regular expression on the channel name
+//	id:C0123456    channel ID, which survives renames
+//
+// Patterns parsed with ParseNamePattern ignore case, and a plain name matches
+// anywhere in the channel name instead.
+type Pattern struct {
+	raw   string
+	kind  patternKind
+	value string
+	re    *regexp.Regexp
+	fold  bool // Names are lowercased before matching
+}
+
+// ParsePattern parses a channel pattern
+func ParsePattern(raw string) (*Pattern, error) {
+	return parsePattern(raw, false)
+}
+
+// ParseNamePattern parses an include or exclude entry. It takes the same patterns
+// as ParsePattern, but ignores case, and a plain name is a substring: "standup"
+// matches #Team-Standup.
+func ParseNamePattern(raw string) (*Pattern, error) {
+	return parsePattern(raw, true)
+}
+
+func parsePattern(raw string, loose bool) (*Pattern, error) {
+	raw = strings.TrimSpace(raw)
+	p := &Pattern{raw: raw, fold: loose}
+
+	switch {
+	case raw == "":
+		return nil, fmt.Errorf("empty pattern")
+	case strings.HasPrefix(raw, "id:"):
+		p.kind = patternID
+		p.value = strings.TrimSpace(strings.TrimPrefix(raw, "id:"))
+		if p.value == "" {
+			return nil, fmt.Errorf("pattern %q: missing channel ID", raw)
+		}
+	case strings.HasPrefix(raw, "re:"):
+		expr := strings.TrimPrefix(raw, "re:")
+		if loose {
+			expr = "(?i)" + expr
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("pattern %q: %w", raw, err)
+		}
+		p.kind = patternRegex
+		p.re = re
+	case strings.ContainsAny(raw, "*?["):
+		if _, err := path.Match(raw, ""); err != nil {
+			return nil, fmt.Errorf("pattern %q: %w", raw, err)
+		}
+		p.kind = patternGlob
+		p.value = p.foldName(strings.TrimPrefix(raw, "#"))
+	case loose:
+		p.kind = patternSubstring
+		p.value = p.foldName(strings.TrimPrefix(raw, "#"))
+	default:
+		p.kind = patternExact
+		p.value = strings.TrimPrefix(raw, "#")
+	}
+	return p, nil
+}
+
+// foldName lowercases name if the pattern ignores case
+func (p *Pattern) foldName(name string) string {
+	if p.fold {
+		return strings.ToLower(name)
+	}
+	return name
+}
+
+// Match reports whether the channel with the given ID and name matches the pattern
+func (p *Pattern) Match(id, name string) bool {
+	switch p.kind {
+	case patternID:
+		return id == p.value
+	case patternRegex:
+		return p.re.MatchString(name)
+	case patternGlob:
+		ok, _ := path.Match(p.value, p.foldName(name))
+		return ok
+	case patternSubstring:
+		return strings.Contains(p.foldName(name), p.value)
+	default:
+		return name == p.value
+	}
+}
+
+// String returns the pattern as written
+func (p *Pattern) String() string {
+	return p.raw
+}
@@ -0,0 +1,62 @@
+package slack
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"workspace-channels-cleaner/policy"
+)
+
+// parsePolicy prepares Policy for a scan
+func (c *Cleaner) parsePolicy() error {
+	c.policy = nil
+	if strings.TrimSpace(c.Policy) == "" {
+		return nil
+	}
+	expr, err := policy.Parse(c.Policy)
+	if err != nil {
+		return fmt.Errorf("invalid policy: %w", err)
+	}
+	c.policy = expr
+	return nil
+}
+
+// historyCutoff returns how far back CheckChannel pages for a channel. A policy
+// that refers to days_idle needs the last activity however old it is, so history
+// is then paged until a message that counts turns up.
+func (c *Cleaner) historyCutoff(rule *RuleMatch) time.Time {
+	if c.policy != nil && c.policy.Uses("days_idle") {
+		return time.Time{}
+	}
+	return c.CutoffFor(rule)
+}
+
+// judgedAt is the moment staleness is judged at: now, unless Cutoff was moved,
+// as for an export analyzed as of the day it was taken
+func (c *Cleaner) judgedAt() time.Time {
+	return c.Cutoff.AddDate(0, 0, c.Days)
+}
+
+// policyChannel describes a checked channel to the policy
+func (c *Cleaner) policyChannel(ch ChannelInfo) policy.Channel {
+	lastSeen := ch.LastSeen
+	if c.SelfActivity {
+		lastSeen = ch.MyLastSeen
+	}
+	if lastSeen.IsZero() {
+		lastSeen = ch.Created
+	}
+	return policy.Channel{
+		Name:        ch.Name,
+		Type:        ch.Type,
+		DaysIdle:    int(c.judgedAt().Sub(lastSeen).Hours() / 24),
+		Members:     ch.NumMembers,
+		Creator:     ch.Creator,
+		Created:     ch.Created,
+		Topic:       ch.Topic,
+		Purpose:     ch.Purpose,
+		IsShared:    ch.IsShared,
+		IsExtShared: ch.IsExtShared,
+	}
+}
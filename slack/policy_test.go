@@ -0,0 +1,91 @@
+package slack
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"workspace-channels-cleaner/slack/fakeapi"
+)
+
+func TestPolicyDecidesStaleChannels(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	small := fakeapi.Channel("C1", "small-old", false)
+	small.NumMembers = 3
+	big := fakeapi.Channel("C2", "big-old", false)
+	big.NumMembers = 40
+	shared := fakeapi.Channel("C3", "shared-old", false)
+	shared.NumMembers = 2
+	shared.IsShared = true
+	recent := fakeapi.Channel("C4", "small-recent", false)
+	recent.NumMembers = 2
+	srv.AddChannel(small, fakeapi.Message("U1", daysAgo(60)))
+	srv.AddChannel(big, fakeapi.Message("U1", daysAgo(60)))
+	srv.AddChannel(shared, fakeapi.Message("U1", daysAgo(60)))
+	srv.AddChannel(recent, fakeapi.Message("U1", daysAgo(35)))
+
+	c := newTestCleaner(t, srv)
+	c.Policy = "days_idle > 45 && members < 5 && !is_shared"
+	if ids := scanIDs(t, c); len(ids) != 1 || ids[0] != "C1" {
+		t.Errorf("got %v, want only small-old", ids)
+	}
+
+	c.Policy = "days_idle >"
+	if _, err := c.GetFilteredChannels(context.Background()); err == nil {
+		t.Error("scan with an unparsable policy succeeded")
+	}
+}
+
+func TestPolicyReportsChannelsBelowTheDaysThreshold(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	quiet15 := fakeapi.Channel("C1", "quiet-for-15", false)
+	quiet15.NumMembers = 10
+	quiet5 := fakeapi.Channel("C2", "quiet-for-5", false)
+	quiet5.NumMembers = 10
+	srv.AddChannel(quiet15, fakeapi.Message("U1", daysAgo(15)))
+	srv.AddChannel(quiet5, fakeapi.Message("U1", daysAgo(5)))
+	few := fakeapi.Channel("C3", "two-members", false)
+	few.NumMembers = 2
+	srv.AddChannel(few, fakeapi.Message("U1", daysAgo(1)))
+
+	c := newTestCleaner(t, srv) // 30 days
+	c.Policy = "days_idle > 10"
+	if ids := scanIDs(t, c); len(ids) != 1 || ids[0] != "C1" {
+		t.Errorf("got %v, want quiet-for-15 although it's within the 30 days", ids)
+	}
+
+	// A policy without days_idle needs no idleness at all
+	c.Policy = "members < 3"
+	if ids := scanIDs(t, c); len(ids) != 1 || ids[0] != "C3" {
+		t.Errorf("got %v, want two-members", ids)
+	}
+}
+
+func TestPolicyPagesBackToTheLastActivity(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	// Three pages of bot posts, all older than the cutoff, hide a human post
+	msgs := []slack.Message{fakeapi.Message("U1", daysAgo(400))}
+	for i := 0; i < 3*historyPageSize; i++ {
+		msgs = append(msgs, fakeapi.BotMessage("B1", daysAgo(40).Add(-time.Duration(i)*time.Hour)))
+	}
+	srv.AddChannel(fakeapi.Channel("C1", "bots-for-a-year", false), msgs...)
+
+	c := newTestCleaner(t, srv)
+	c.Activity = NewActivityFilter([]string{"bot_message"}, nil)
+	c.Policy = "days_idle > 365"
+	channels, err := c.GetFilteredChannels(context.Background())
+	if err != nil {
+		t.Fatalf("GetFilteredChannels: %v", err)
+	}
+	if len(channels) != 1 || channels[0].LastSeen.Unix() != daysAgo(400).Unix() {
+		t.Fatalf("got %+v, want bots-for-a-year last seen 400 days ago", channels)
+	}
+	if calls := srv.Calls("conversations.history"); calls != 4 {
+		t.Errorf("made %d history calls, want all 4 pages", calls)
+	}
+}
@@ -0,0 +1,33 @@
+package slack
+
+import (
+	"time"
+)
+
+// ScanProgress is a snapshot of a running scan, passed to Cleaner.Progress
+type ScanProgress struct {
+	Pages     int       // Channel list pages fetched
+	Listed    bool      // Every page has been fetched, so Total is final
+	Total     int       // Channels queued for a history check
+	Checked   int       // Channels whose history has been checked
+	Matched   int       // Stale channels found so far
+	Cached    int       // Checked channels whose result came from the scan cache
+	Errors    int       // Channels whose activity could not be determined
+	WaitUntil time.Time // While in the future, the scan is waiting on a rate limit until then
+}
+
+// Remaining returns how many queued channels have not been checked yet
+func (p ScanProgress) Remaining() int {
+	return p.Total - p.Checked
+}
+
+// updateProgress applies update to the scan's progress and reports the result.
+// Progress is called with the lock held, so calls never overlap and arrive in order.
+func (c *Cleaner) updateProgress(update func(p *ScanProgress)) {
+	c.progressMu.Lock()
+	defer c.progressMu.Unlock()
+	update(&c.progress)
+	if c.Progress != nil {
+		c.Progress(c.progress)
+	}
+}
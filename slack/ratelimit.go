@@ -0,0 +1,329 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// Tier is a workspace API rate limit tier
+type Tier int
+
+const (
+	Tier1 Tier = iota + 1
+	Tier2
+	Tier3
+	Tier4
+)
+
+// DefaultTierRates are the documented requests per minute of each tier
+var DefaultTierRates = map[Tier]float64{
+	Tier1: 1,
+	Tier2: 20,
+	Tier3: 50,
+	Tier4: 100,
+}
+
+// methodTiers maps the API methods the cleaner calls to their tier
+var methodTiers = map[string]Tier{
+	"auth.test":             Tier4,
+	"conversations.list":    Tier2,
+	"conversations.history": Tier3,
+	"conversations.replies": Tier3,
+	"conversations.leave":   Tier3,
+	"conversations.join":    Tier3,
+	"conversations.archive": Tier2,
+	"chat.postMessage":      Tier3, // Really about one message per second per channel
+	"users.prefs.set":       Tier3,
+}
+
+const (
+	maxConcurrency      = 5                // Concurrent requests per method while the API is happy
+	maxRateLimitRetries = 5                // Attempts per call before giving up on a rate-limited method
+	maxTransientRetries = 1                // Retries per call after a timeout or server error
+	transientRetryDelay = time.Second      // Wait before retrying after a timeout or server error
+	defaultRetryAfter   = 30 * time.Second // Wait when a rate-limited response carries no Retry-After
+	rateCeiling         = 2                // Tiers are minimums, so a method may speed up to this multiple
+	successesToGrow     = 20               // Successful calls in a row before rate and concurrency grow
+)
+
+// Limiter paces API calls with a token bucket per method, sized by the method's
+// tier. The API counts limits per method and workspace, so a Limiter is meant to be
+// shared by every goroutine and cleaner in the process. A rate-limited response
+// pauses the method for everyone for the Retry-After period, halves its rate and
+// concurrency, and both grow back slowly while calls keep succeeding.
+type Limiter struct {
+	// DefaultRetryAfter is the pause used when a rate-limited response has no Retry-After
+	DefaultRetryAfter time.Duration
+
+	// TransientRetryDelay is the pause before retrying a call that timed out or got a server error
+	TransientRetryDelay time.Duration
+
+	rates   map[Tier]float64
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// bucket is the state of a single method. Fields are guarded by Limiter.mu.
+type bucket struct {
+	nominal     float64 // Tier rate in requests per second
+	rate        float64 // Current rate in requests per second
+	tokens      float64
+	burst       float64
+	last        time.Time
+	pausedUntil time.Time
+	successes   int
+
+	concurrency int
+	active      int
+	changed     chan struct{} // Closed when a slot frees up or concurrency grows
+}
+
+// DefaultLimiter is shared by every cleaner created with NewCleaner
+var DefaultLimiter = NewLimiter(DefaultTierRates)
+
+// NewLimiter creates a limiter from requests per minute for each tier
+func NewLimiter(rates map[Tier]float64) *Limiter {
+	return &Limiter{
+		DefaultRetryAfter:   defaultRetryAfter,
+		TransientRetryDelay: transientRetryDelay,
+		rates:               rates,
+		buckets:             make(map[string]*bucket),
+	}
+}
+
+// bucket must be called with l.mu held
+func (l *Limiter) bucket(method string) *bucket {
+	b, ok := l.buckets[method]
+	if ok {
+		return b
+	}
+	tier, ok := methodTiers[method]
+	if !ok {
+		tier = Tier3
+	}
+	perMinute := l.rates[tier]
+	if perMinute <= 0 {
+		perMinute = DefaultTierRates[tier]
+	}
+	b = &bucket{
+		nominal:     perMinute / 60,
+		rate:        perMinute / 60,
+		burst:       max(1, perMinute/5),
+		last:        time.Now(),
+		concurrency: maxConcurrency,
+		changed:     make(chan struct{}),
+	}
+	b.tokens = b.burst
+	l.buckets[method] = b
+	return b
+}
+
+// Acquire blocks until a request to method may start, taking a concurrency slot and
+// a token. Every successful Acquire must be followed by Release.
+func (l *Limiter) Acquire(ctx context.Context, method string) error {
+	for {
+		l.mu.Lock()
+		b := l.bucket(method)
+		if b.active >= b.concurrency {
+			changed := b.changed
+			l.mu.Unlock()
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-changed:
+			}
+			continue
+		}
+
+		now := time.Now()
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+		var wait time.Duration
+		if now.Before(b.pausedUntil) {
+			wait = b.pausedUntil.Sub(now)
+		} else if b.tokens < 1 {
+			wait = time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		} else {
+			b.tokens--
+			b.active++
+			l.mu.Unlock()
+			return nil
+		}
+		l.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Release returns the concurrency slot taken by Acquire
+func (l *Limiter) Release(method string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.bucket(method)
+	b.active--
+	b.notify()
+}
+
+// Success records a call that wasn't rate limited. After a run of them the
+// method's rate and concurrency grow back towards their ceiling.
+func (l *Limiter) Success(method string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.bucket(method)
+	b.successes++
+	if b.successes < successesToGrow {
+		return
+	}
+	b.successes = 0
+	b.rate = min(b.nominal*rateCeiling, b.rate+b.nominal/10)
+	if b.concurrency < maxConcurrency {
+		b.concurrency++
+		b.notify()
+	}
+}
+
+// Backoff records a rate-limited call: every caller of method waits until
+// retryAfter has passed, and the method's rate and concurrency are halved.
+// It returns when the method may be called again.
+func (l *Limiter) Backoff(method string, retryAfter time.Duration) time.Time {
+	if retryAfter <= 0 {
+		retryAfter = l.DefaultRetryAfter
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.bucket(method)
+	if until := time.Now().Add(retryAfter); until.After(b.pausedUntil) {
+		b.pausedUntil = until
+	}
+	b.successes = 0
+	b.tokens = min(b.tokens, 1)
+	b.rate = max(b.nominal/4, b.rate/2)
+	b.concurrency = max(1, b.concurrency/2)
+	return b.pausedUntil
+}
+
+// notify must be called with l.mu held
+func (b *bucket) notify() {
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
+
+// Rate returns the current requests per minute and concurrency of method
+func (l *Limiter) Rate(method string) (float64, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.bucket(method)
+	return b.rate * 60, b.concurrency
+}
+
+// Summary describes the effective rate of every method used so far
+func (l *Limiter) Summary() string {
+	l.mu.Lock()
+	methods := make([]string, 0, len(l.buckets))
+	for method := range l.buckets {
+		methods = append(methods, method)
+	}
+	l.mu.Unlock()
+	sort.Strings(methods)
+
+	summary := ""
+	for _, method := range methods {
+		rate, concurrency := l.Rate(method)
+		if summary != "" {
+			summary += ", "
+		}
+		summary += fmt.Sprintf("%s %.0f/min ×%d", method, rate, concurrency)
+	}
+	return summary
+}
+
+// rateLimitDelay reports whether err is a rate-limited response and how long the
+// API asked to wait; zero means it didn't say
+func rateLimitDelay(err error) (time.Duration, bool) {
+	var rateErr *slack.RateLimitedError
+	if errors.As(err, &rateErr) {
+		return rateErr.RetryAfter, true
+	}
+	var apiErr slack.SlackErrorResponse
+	if errors.As(err, &apiErr) && (apiErr.Err == "rate_limited" || apiErr.Err == "ratelimited") {
+		return 0, true
+	}
+	return 0, false
+}
+
+// transientError reports whether err is a timeout or server error that is worth
+// retrying once; API errors such as channel_not_found are not
+func transientError(err error) bool {
+	var statusErr slack.StatusCodeError
+	if errors.As(err, &statusErr) {
+		return statusErr.Code >= 500
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// call runs fn, a single call to the API method, under the shared limiter.
+// Rate-limited attempts are retried after the pause the API asked for, and
+// timeouts and server errors are retried once after TransientRetryDelay.
+// Without a Limiter, fn runs unpaced, as suits an offline export.
+func (c *Cleaner) call(ctx context.Context, method string, fn func() error) error {
+	if c.Limiter == nil {
+		return fn()
+	}
+	transientRetries := 0
+	for attempt := 1; ; attempt++ {
+		if err := c.Limiter.Acquire(ctx, method); err != nil {
+			return err
+		}
+		err := fn()
+		c.Limiter.Release(method)
+
+		retryAfter, limited := rateLimitDelay(err)
+		if !limited {
+			if err == nil {
+				c.Limiter.Success(method)
+				return nil
+			}
+			if !transientError(err) || transientRetries >= maxTransientRetries {
+				return err
+			}
+			transientRetries++
+			if c.Verbose {
+				fmt.Fprintf(c.Out, "⚠️  %s failed (%v); retrying in %v\n", method, err, c.Limiter.TransientRetryDelay)
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(c.Limiter.TransientRetryDelay):
+			}
+			continue
+		}
+		if attempt >= maxRateLimitRetries {
+			return fmt.Errorf("%s still rate limited after %d attempts: %w", method, attempt, err)
+		}
+
+		until := c.Limiter.Backoff(method, retryAfter)
+		c.updateProgress(func(p *ScanProgress) {
+			if until.After(p.WaitUntil) {
+				p.WaitUntil = until
+			}
+		})
+		if c.Verbose {
+			rate, concurrency := c.Limiter.Rate(method)
+			fmt.Fprintf(c.Out, "⏳ %s rate limited; waiting %v, then %.0f/min with %d concurrent\n", method, time.Until(until).Round(time.Second), rate, concurrency)
+		}
+	}
+}
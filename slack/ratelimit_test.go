@@ -0,0 +1,187 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"workspace-channels-cleaner/slack/fakeapi"
+)
+
+// fastRates keep tests quick while still going through the token buckets
+var fastRates = map[Tier]float64{Tier1: 6000, Tier2: 6000, Tier3: 6000, Tier4: 6000}
+
+func newTestCleaner(t *testing.T, srv *fakeapi.Server) *Cleaner {
+	t.Helper()
+	c := NewCleanerWithAPI(srv.Client(), 100, []string{"public_channel"}, 30, false)
+	c.SkipList = nil
+	c.Out = io.Discard
+	c.JournalPath = filepath.Join(t.TempDir(), "journal.json")
+	c.NoticesPath = filepath.Join(t.TempDir(), "notices.json")
+	c.MutedPath = filepath.Join(t.TempDir(), "muted.json")
+	c.Limiter = NewLimiter(fastRates)
+	return c
+}
+
+func addStaleChannels(srv *fakeapi.Server, n int) {
+	old := time.Now().AddDate(0, 0, -90)
+	for i := 0; i < n; i++ {
+		srv.AddChannel(fakeapi.Channel(fmt.Sprintf("C%03d", i), fmt.Sprintf("stale-%d", i), false), fakeapi.Message("U1", old))
+	}
+}
+
+func TestScanPausesEveryWorkerOnRetryAfter(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	addStaleChannels(srv, 10)
+	srv.RateLimit("conversations.history", 1, time.Second)
+
+	c := newTestCleaner(t, srv)
+	channels, err := c.GetFilteredChannels(context.Background())
+	if err != nil {
+		t.Fatalf("GetFilteredChannels: %v", err)
+	}
+	if len(channels) != 10 {
+		t.Fatalf("got %d stale channels, want 10", len(channels))
+	}
+
+	times := srv.CallTimes("conversations.history")
+	if len(times) != 11 {
+		t.Fatalf("got %d history calls, want 11 (10 channels + 1 retry)", len(times))
+	}
+	// Calls already in flight may land right after the 429; nothing else may
+	// start until the Retry-After period is over
+	limited := times[0]
+	for _, at := range times[1:] {
+		if d := at.Sub(limited); d > 200*time.Millisecond && d < 900*time.Millisecond {
+			t.Errorf("history call %v after the 429, inside the Retry-After pause", d)
+		}
+	}
+
+	rate, concurrency := c.Limiter.Rate("conversations.history")
+	if rate >= fastRates[Tier3] || concurrency >= maxConcurrency {
+		t.Errorf("after a 429 got %.0f/min with %d concurrent, want both reduced", rate, concurrency)
+	}
+}
+
+func TestRateLimitedBodyUsesDefaultRetryAfter(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	addStaleChannels(srv, 3)
+	srv.RateLimitBody("conversations.list", 2)
+
+	c := newTestCleaner(t, srv)
+	c.Limiter.DefaultRetryAfter = 20 * time.Millisecond
+	channels, err := c.GetFilteredChannels(context.Background())
+	if err != nil {
+		t.Fatalf("GetFilteredChannels: %v", err)
+	}
+	if len(channels) != 3 {
+		t.Errorf("got %d stale channels, want 3", len(channels))
+	}
+	if n := srv.Calls("conversations.list"); n != 3 {
+		t.Errorf("got %d list calls, want 3", n)
+	}
+}
+
+func TestLeaveRetriesAfterRateLimit(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	addStaleChannels(srv, 1)
+	srv.RateLimit("conversations.leave", 1, time.Second)
+
+	c := newTestCleaner(t, srv)
+	report := c.LeaveChannels([]ChannelInfo{{ID: "C000", Name: "stale-0", Type: "public"}})
+	if len(report.Succeeded) != 1 || len(report.Failed) != 0 {
+		t.Fatalf("got %d left and %d failed, want 1 left", len(report.Succeeded), len(report.Failed))
+	}
+	if srv.IsMember("C000") {
+		t.Error("still a member after leaving")
+	}
+	if n := srv.Calls("conversations.leave"); n != 2 {
+		t.Errorf("got %d leave calls, want 2", n)
+	}
+}
+
+func TestLeaveGivesUpWhenStillRateLimited(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	addStaleChannels(srv, 2)
+	srv.RateLimitBody("conversations.leave", maxRateLimitRetries)
+
+	c := newTestCleaner(t, srv)
+	c.Limiter.DefaultRetryAfter = 10 * time.Millisecond
+	report := c.LeaveChannels([]ChannelInfo{
+		{ID: "C000", Name: "stale-0", Type: "public"},
+		{ID: "C001", Name: "stale-1", Type: "public"},
+	})
+	if len(report.Failed) != 1 || report.Failed[0].Channel.ID != "C000" {
+		t.Fatalf("got failures %+v, want only C000", report.Failed)
+	}
+	if len(report.Succeeded) != 1 || report.Succeeded[0].ID != "C001" {
+		t.Errorf("got successes %+v, want only C001", report.Succeeded)
+	}
+}
+
+func TestScanRetriesAServerErrorOnce(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	addStaleChannels(srv, 1)
+	srv.ServerError("conversations.history", 1)
+
+	c := newTestCleaner(t, srv)
+	c.Limiter.TransientRetryDelay = 10 * time.Millisecond
+	channels, err := c.GetFilteredChannels(context.Background())
+	if err != nil {
+		t.Fatalf("GetFilteredChannels: %v", err)
+	}
+	if len(channels) != 1 || channels[0].Undetermined() {
+		t.Fatalf("got %+v, want stale-0 checked after the retry", channels)
+	}
+	if n := srv.Calls("conversations.history"); n != 2 {
+		t.Errorf("got %d history calls, want 2", n)
+	}
+
+	srv.ServerError("conversations.history", 2)
+	channels, err = c.GetFilteredChannels(context.Background())
+	if err != nil {
+		t.Fatalf("GetFilteredChannels: %v", err)
+	}
+	if len(channels) != 1 || !channels[0].Undetermined() {
+		t.Errorf("got %+v, want stale-0 undetermined after two server errors", channels)
+	}
+}
+
+func TestLimiterRecoversAfterSuccesses(t *testing.T) {
+	l := NewLimiter(DefaultTierRates)
+	nominal, _ := l.Rate("conversations.history")
+
+	l.Backoff("conversations.history", time.Millisecond)
+	rate, concurrency := l.Rate("conversations.history")
+	if rate != nominal/2 || concurrency != maxConcurrency/2 {
+		t.Fatalf("after backoff got %.0f/min with %d concurrent, want %.0f/min with %d", rate, concurrency, nominal/2, maxConcurrency/2)
+	}
+
+	for i := 0; i < successesToGrow; i++ {
+		l.Success("conversations.history")
+	}
+	grown, grownConcurrency := l.Rate("conversations.history")
+	if grown <= rate || grownConcurrency != concurrency+1 {
+		t.Errorf("after %d successes got %.0f/min with %d concurrent, want growth from %.0f/min with %d", successesToGrow, grown, grownConcurrency, rate, concurrency)
+	}
+}
+
+func TestAcquireStopsWhenContextEnds(t *testing.T) {
+	l := NewLimiter(DefaultTierRates)
+	l.Backoff("conversations.list", time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := l.Acquire(ctx, "conversations.list"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Acquire during a pause returned %v, want context.DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,122 @@
+package slack
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"workspace-channels-cleaner/config"
+)
+
+// ActionIgnore is a rule action only: channels it matches are never reported
+const ActionIgnore Action = "ignore"
+
+// Rule sets the staleness threshold and action for the channels it matches
+type Rule struct {
+	Name    string   // Shown in the results
+	Pattern *Pattern // nil matches every name
+	Type    string   // "public" or "private"; empty matches both
+	Days    int      // Staleness threshold; 0 keeps Cleaner.Days
+	Action  Action   // ActionLeave, ActionMute or ActionIgnore
+}
+
+// RuleMatch records the rule that decided a channel's staleness
+type RuleMatch struct {
+	Name   string `json:"name"`
+	Days   int    `json:"days"`
+	Action Action `json:"action"`
+}
+
+// String describes the match for the results, e.g. "incidents (7d, mute)"
+func (m *RuleMatch) String() string {
+	if m == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s (%dd, %s)", m.Name, m.Days, m.Action)
+}
+
+// ParseRules parses the rules of the configuration, keeping their order
+func ParseRules(rules []config.RuleConfig) ([]Rule, error) {
+	parsed := make([]Rule, 0, len(rules))
+	for i, rc := range rules {
+		rule := Rule{Name: rc.Name, Type: rc.Type, Days: rc.Days, Action: Action(rc.Action)}
+		if rule.Action == "" {
+			rule.Action = ActionLeave
+		}
+		switch rule.Action {
+		case ActionLeave, ActionMute, ActionIgnore:
+		default:
+			return nil, fmt.Errorf("rule %d: unknown action %q", i+1, rc.Action)
+		}
+		if strings.TrimSpace(rc.Pattern) != "" {
+			pattern, err := ParsePattern(rc.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: %w", i+1, err)
+			}
+			rule.Pattern = pattern
+		}
+		if rule.Name == "" {
+			rule.Name = strings.TrimSpace(rc.Pattern)
+		}
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule %d", i+1)
+		}
+		parsed = append(parsed, rule)
+	}
+	return parsed, nil
+}
+
+// Matches reports whether the rule applies to the given channel
+func (r Rule) Matches(id, name, channelType string) bool {
+	if r.Type != "" && r.Type != channelType {
+		return false
+	}
+	return r.Pattern == nil || r.Pattern.Match(id, name)
+}
+
+// matchRule returns the first of the cleaner's rules matching the channel, or nil
+func (c *Cleaner) matchRule(id, name, channelType string) *RuleMatch {
+	for _, r := range c.rules {
+		if !r.Matches(id, name, channelType) {
+			continue
+		}
+		days := r.Days
+		if days == 0 {
+			days = c.Days
+		}
+		return &RuleMatch{Name: r.Name, Days: days, Action: r.Action}
+	}
+	return nil
+}
+
+// CutoffFor returns the cutoff for a channel the given rule matched; nil means
+// no rule did and the cleaner's own Cutoff applies
+func (c *Cleaner) CutoffFor(match *RuleMatch) time.Time {
+	if match == nil {
+		return c.Cutoff
+	}
+	// Relative to Cutoff rather than now, so a cutoff moved by --as-of or a plan still holds
+	return c.Cutoff.AddDate(0, 0, c.Days-match.Days)
+}
+
+// parseRules prepares Rules for a scan
+func (c *Cleaner) parseRules() error {
+	rules, err := ParseRules(c.Rules)
+	if err != nil {
+		return fmt.Errorf("invalid rules: %w", err)
+	}
+	c.rules = rules
+	return nil
+}
+
+// splitRuleMutes separates the channels whose rule says to mute rather than leave them
+func splitRuleMutes(channels []ChannelInfo) (leave, mute []ChannelInfo) {
+	for _, ch := range channels {
+		if ch.Rule != nil && ch.Rule.Action == ActionMute {
+			mute = append(mute, ch)
+		} else {
+			leave = append(leave, ch)
+		}
+	}
+	return leave, mute
+}
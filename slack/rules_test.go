@@ -0,0 +1,68 @@
+package slack
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"workspace-channels-cleaner/config"
+	"workspace-channels-cleaner/slack/fakeapi"
+)
+
+func TestRulesSetThresholdAndActionPerChannel(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	days := func(n int) time.Time { return time.Now().AddDate(0, 0, -n) }
+	srv.AddChannel(fakeapi.Channel("C1", "inc-42", false), fakeapi.Message("U1", days(10)))
+	srv.AddChannel(fakeapi.Channel("C2", "proj-active", false), fakeapi.Message("U1", days(40)))
+	srv.AddChannel(fakeapi.Channel("C3", "proj-stale", false), fakeapi.Message("U1", days(90)))
+	srv.AddChannel(fakeapi.Channel("C4", "random", false), fakeapi.Message("U1", days(40)))
+	srv.AddChannel(fakeapi.Channel("C5", "announce-all", false), fakeapi.Message("U1", days(90)))
+
+	c := newTestCleaner(t, srv)
+	c.Rules = []config.RuleConfig{
+		{Name: "incidents", Pattern: "inc-*", Days: 7},
+		{Pattern: "proj-*", Days: 60, Action: config.RuleMute},
+		{Pattern: "announce-*", Action: config.RuleIgnore},
+	}
+	channels, err := c.GetFilteredChannels(context.Background())
+	if err != nil {
+		t.Fatalf("GetFilteredChannels: %v", err)
+	}
+
+	got := make(map[string]*RuleMatch)
+	for _, ch := range channels {
+		got[ch.Name] = ch.Rule
+	}
+	if len(got) != 3 {
+		t.Fatalf("found %v, want inc-42, proj-stale and random", got)
+	}
+	if r := got["inc-42"]; r == nil || r.Name != "incidents" || r.Days != 7 || r.Action != ActionLeave {
+		t.Errorf("inc-42 matched %v, want incidents (7d, leave)", r)
+	}
+	if r := got["proj-stale"]; r == nil || r.Name != "proj-*" || r.Action != ActionMute {
+		t.Errorf("proj-stale matched %v, want proj-* (60d, mute)", r)
+	}
+	if r, ok := got["random"]; !ok || r != nil {
+		t.Errorf("random matched %v, want no rule", r)
+	}
+
+	report := c.LeaveChannels(channels)
+	if len(report.Succeeded) != 2 || len(report.Muted) != 1 || report.Muted[0].ID != "C3" {
+		t.Fatalf("left %d and muted %+v, want inc-42 and random left and proj-stale muted", len(report.Succeeded), report.Muted)
+	}
+	if !srv.IsMember("C3") || !srv.IsMuted("C3") {
+		t.Error("proj-stale was left instead of muted")
+	}
+}
+
+func TestInvalidRuleFailsTheScan(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+
+	c := newTestCleaner(t, srv)
+	c.Rules = []config.RuleConfig{{Pattern: "re:inc-(", Days: 7}}
+	if _, err := c.GetFilteredChannels(context.Background()); err == nil {
+		t.Error("scan with an invalid rule pattern succeeded")
+	}
+}
@@ -0,0 +1,236 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"workspace-channels-cleaner/slack/fakeapi"
+)
+
+func TestScanReportsAnOutcomeForEveryChannel(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	now := time.Now()
+	srv.AddChannel(fakeapi.Channel("C1", "stale", false), fakeapi.Message("U1", now.AddDate(0, 0, -90)))
+	srv.AddChannel(fakeapi.Channel("C2", "active", false), fakeapi.Message("U1", now))
+	srv.AddChannel(fakeapi.Channel("C3", "broken", false), fakeapi.Message("U1", now))
+	srv.BreakHistory("C3", "internal_error")
+
+	c := newTestCleaner(t, srv)
+	var progress ScanProgress
+	c.Progress = func(p ScanProgress) { progress = p }
+	channels, err := c.GetFilteredChannels(context.Background())
+	if err != nil {
+		t.Fatalf("GetFilteredChannels: %v", err)
+	}
+
+	if len(channels) != 2 {
+		t.Fatalf("got %d channels, want the stale and the broken one", len(channels))
+	}
+	if channels[0].ID != "C1" || channels[0].Undetermined() {
+		t.Errorf("first channel is %+v, want stale C1", channels[0])
+	}
+	if channels[1].ID != "C3" || channels[1].Error != "internal_error" {
+		t.Errorf("last channel is %+v, want C3 with error internal_error", channels[1])
+	}
+	if progress.Total != 3 || progress.Checked != 3 || progress.Matched != 1 || progress.Errors != 1 {
+		t.Errorf("final progress %+v, want 3 checked with 1 stale and 1 error", progress)
+	}
+}
+
+func TestLeaveSkipsUndeterminedChannels(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	addStaleChannels(srv, 2)
+
+	c := newTestCleaner(t, srv)
+	report := c.LeaveChannels([]ChannelInfo{
+		{ID: "C000", Name: "stale-0", Type: "public"},
+		{ID: "C001", Name: "stale-1", Type: "public", Error: "internal_error"},
+	})
+
+	if len(report.Succeeded) != 1 || report.Succeeded[0].ID != "C000" {
+		t.Errorf("left %+v, want only stale-0", report.Succeeded)
+	}
+	if len(report.Skipped) != 1 || report.Skipped[0].Channel.ID != "C001" {
+		t.Fatalf("skipped %+v, want the undetermined stale-1", report.Skipped)
+	}
+	if !srv.IsMember("C001") || srv.Calls("conversations.leave") != 1 {
+		t.Error("the undetermined channel was left")
+	}
+}
+
+func TestScanManyChannelsConcurrently(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	const n = 200
+	addStaleChannels(srv, n)
+	for i := 0; i < n; i += 10 {
+		srv.BreakHistory(fmt.Sprintf("C%03d", i), "internal_error")
+	}
+
+	c := newTestCleaner(t, srv)
+	c.Limit = 30 // Several list pages while workers are busy
+	var mu sync.Mutex
+	found := make(map[string]int)
+	c.Found = func(ch ChannelInfo) {
+		mu.Lock()
+		found[ch.ID]++
+		mu.Unlock()
+	}
+	channels, err := c.GetFilteredChannels(context.Background())
+	if err != nil {
+		t.Fatalf("GetFilteredChannels: %v", err)
+	}
+
+	if len(channels) != n || len(found) != n {
+		t.Fatalf("got %d channels and %d found, want %d of each", len(channels), len(found), n)
+	}
+	errored := 0
+	for _, ch := range channels {
+		if found[ch.ID] != 1 {
+			t.Errorf("#%s reported %d times", ch.Name, found[ch.ID])
+		}
+		if ch.Undetermined() {
+			errored++
+		}
+	}
+	if errored != n/10 {
+		t.Errorf("got %d undetermined channels, want %d", errored, n/10)
+	}
+	for _, ch := range channels[:n-n/10] {
+		if ch.Undetermined() {
+			t.Fatalf("undetermined #%s sorted before determined channels", ch.Name)
+		}
+	}
+}
+
+func TestScanStopsWhenCancelled(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	addStaleChannels(srv, 50)
+
+	c := newTestCleaner(t, srv)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c.Found = func(ChannelInfo) { cancel() }
+
+	channels, err := c.GetFilteredChannels(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got error %v, want context.Canceled", err)
+	}
+	if channels != nil {
+		t.Errorf("got %d channels from a cancelled scan, want none", len(channels))
+	}
+	if calls := srv.Calls("conversations.history"); calls >= 50 {
+		t.Errorf("made %d history calls after cancelling, want the scan to stop early", calls)
+	}
+}
+
+func TestScanFailsWhenListingFails(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	addStaleChannels(srv, 5)
+	srv.Fail("conversations.list", 1, "invalid_cursor")
+
+	c := newTestCleaner(t, srv)
+	if _, err := c.GetFilteredChannels(context.Background()); err == nil || err.Error() != "invalid_cursor" {
+		t.Fatalf("got error %v, want invalid_cursor", err)
+	}
+}
+
+func TestScanCarriesChannelDetails(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	created := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	ch := fakeapi.Channel("C1", "partners", false)
+	ch.NumMembers = 7
+	ch.Creator = "U9"
+	ch.Created = slack.JSONTime(created.Unix())
+	ch.Topic.Value = "Partner escalations"
+	ch.Purpose.Value = "Talk to the partner team"
+	ch.IsShared = true
+	ch.IsExtShared = true
+	srv.AddChannel(ch, fakeapi.Message("U1", time.Now().AddDate(0, 0, -90)))
+
+	c := newTestCleaner(t, srv)
+	channels, err := c.GetFilteredChannels(context.Background())
+	if err != nil {
+		t.Fatalf("GetFilteredChannels: %v", err)
+	}
+	if len(channels) != 1 {
+		t.Fatalf("got %d channels, want 1", len(channels))
+	}
+	got := channels[0]
+	if got.NumMembers != 7 || got.Creator != "U9" || !got.Created.Equal(created) ||
+		got.Topic != "Partner escalations" || got.Purpose != "Talk to the partner team" ||
+		!got.IsShared || !got.IsExtShared || got.IsGeneral {
+		t.Errorf("channel details are %+v", got)
+	}
+}
+
+func TestScanFindsStaleChannelsAgainstTheFakeAPI(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	now := time.Now()
+	srv.AddChannel(fakeapi.Channel("C1", "old", false), fakeapi.Message("U1", now.AddDate(0, 0, -60)))
+	srv.AddChannel(fakeapi.Channel("C2", "recent", false), fakeapi.Message("U1", now.AddDate(0, 0, -2)))
+	srv.AddChannel(fakeapi.Channel("C3", "older", false),
+		fakeapi.Message("U1", now.AddDate(0, 0, -200)), fakeapi.Message("U2", now.AddDate(0, 0, -90)))
+	left := fakeapi.Channel("C4", "not-a-member", false)
+	left.IsMember = false
+	srv.AddChannel(left, fakeapi.Message("U1", now.AddDate(0, 0, -90)))
+	srv.AddChannel(fakeapi.Channel("C5", "secret", true), fakeapi.Message("U1", now.AddDate(0, 0, -90)))
+
+	c := newTestCleaner(t, srv)
+	c.Limit = 2 // Page through the channel list
+	channels, err := c.GetFilteredChannels(context.Background())
+	if err != nil {
+		t.Fatalf("GetFilteredChannels: %v", err)
+	}
+
+	if len(channels) != 2 || channels[0].ID != "C3" || channels[1].ID != "C1" {
+		t.Fatalf("got %+v, want older then old", channels)
+	}
+	if want := now.AddDate(0, 0, -90).Unix(); channels[0].LastSeen.Unix() != want {
+		t.Errorf("#older last seen %v, want its newest message", channels[0].LastSeen)
+	}
+	if calls := srv.Calls("conversations.list"); calls != 2 {
+		t.Errorf("made %d list calls, want 2 pages", calls)
+	}
+}
+
+func TestScanReportsEmptyChannelsOldestCreatedFirst(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	newer := fakeapi.Channel("C1", "empty-newer", false)
+	newer.Created = slack.JSONTime(time.Now().AddDate(0, -2, 0).Unix())
+	older := fakeapi.Channel("C2", "empty-older", false)
+	older.Created = slack.JSONTime(time.Now().AddDate(-1, 0, 0).Unix())
+	joinsOnly := fakeapi.Channel("C3", "joins-only", false)
+	joinsOnly.Created = slack.JSONTime(time.Now().AddDate(0, -6, 0).Unix())
+	srv.AddChannel(fakeapi.Channel("C4", "stale", false), fakeapi.Message("U1", time.Now().AddDate(0, 0, -90)))
+	srv.AddChannel(newer)
+	srv.AddChannel(fakeapi.Channel("C5", "active", false), fakeapi.Message("U1", time.Now()))
+	srv.AddChannel(joinsOnly, fakeapi.EventMessage("channel_join", "U1", time.Now()))
+	srv.AddChannel(older)
+
+	c := newTestCleaner(t, srv)
+	c.Activity = NewActivityFilter([]string{"channel_join"}, nil)
+	if ids := scanIDs(t, c); len(ids) != 1 || ids[0] != "C4" {
+		t.Errorf("got %v without IncludeEmpty, want only the stale channel", ids)
+	}
+
+	c.IncludeEmpty = true
+	ids := scanIDs(t, c)
+	want := []string{"C2", "C3", "C1", "C4"}
+	if fmt.Sprint(ids) != fmt.Sprint(want) {
+		t.Errorf("got %v, want the empty channels oldest created first, then the stale one: %v", ids, want)
+	}
+}
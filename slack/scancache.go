@@ -0,0 +1,186 @@
+package slack
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultScanCachePath is where scan results are cached between runs
+const DefaultScanCachePath = "config/scancache.json"
+
+// CacheEntry is the cached outcome of checking one channel's history
+type CacheEntry struct {
+	LastSeen   time.Time `json:"last_seen"`
+	Source     string    `json:"source,omitempty"`
+	MyLastSeen time.Time `json:"my_last_seen"`
+	FetchedAt  time.Time `json:"fetched_at"`
+}
+
+// workspaceCache holds the entries of one workspace. Settings describes the scan
+// settings the entries were computed with; entries are dropped when they change.
+type workspaceCache struct {
+	Settings string                `json:"settings"`
+	Channels map[string]CacheEntry `json:"channels"`
+}
+
+// ScanCache holds channel activity keyed by workspace and channel ID.
+// It is safe for concurrent use.
+type ScanCache struct {
+	mu         sync.Mutex
+	Workspaces map[string]*workspaceCache `json:"workspaces"`
+}
+
+// LoadScanCache reads a scan cache; a missing file gives an empty cache
+func LoadScanCache(path string) (*ScanCache, error) {
+	cache := &ScanCache{Workspaces: make(map[string]*workspaceCache)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache, nil
+	}
+	if err := json.Unmarshal(data, cache); err != nil {
+		return nil, fmt.Errorf("failed to parse scan cache: %w", err)
+	}
+	if cache.Workspaces == nil {
+		cache.Workspaces = make(map[string]*workspaceCache)
+	}
+	return cache, nil
+}
+
+// SaveScanCache writes the cache, leaving out entries fetched before expireBefore
+func SaveScanCache(path string, cache *ScanCache, expireBefore time.Time) error {
+	cache.mu.Lock()
+	for _, ws := range cache.Workspaces {
+		for id, entry := range ws.Channels {
+			if entry.FetchedAt.Before(expireBefore) {
+				delete(ws.Channels, id)
+			}
+		}
+	}
+	data, err := json.MarshalIndent(cache, "", "  ")
+	cache.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal scan cache: %w", err)
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
+// Get returns the cached entry of a channel computed with the given settings
+func (sc *ScanCache) Get(teamID, settings, channelID string) (CacheEntry, bool) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	ws, ok := sc.Workspaces[teamID]
+	if !ok || ws.Settings != settings {
+		return CacheEntry{}, false
+	}
+	entry, ok := ws.Channels[channelID]
+	return entry, ok
+}
+
+// Put stores the entry of a channel, dropping the workspace's entries if they were
+// computed with different settings
+func (sc *ScanCache) Put(teamID, settings, channelID string, entry CacheEntry) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	ws, ok := sc.Workspaces[teamID]
+	if !ok || ws.Settings != settings {
+		ws = &workspaceCache{Settings: settings, Channels: make(map[string]CacheEntry)}
+		sc.Workspaces[teamID] = ws
+	}
+	ws.Channels[channelID] = entry
+}
+
+// cacheSettings describes everything that changes what CheckChannel returns
+func (c *Cleaner) cacheSettings() string {
+	keys := func(set map[string]bool) string {
+		list := make([]string, 0, len(set))
+		for k := range set {
+			list = append(list, k)
+		}
+		sort.Strings(list)
+		return strings.Join(list, ",")
+	}
+	// A rule's days move the cutoff CheckChannel pages back to
+	var rules []string
+	for _, r := range c.Rules {
+		rules = append(rules, fmt.Sprintf("%s/%s/%d", r.Pattern, r.Type, r.Days))
+	}
+	// So does a policy on days_idle, back to the last activity however old
+	unbounded := c.historyCutoff(nil).IsZero()
+	return fmt.Sprintf("days=%d threads=%t self=%t subtypes=%s users=%s rules=%s unbounded=%t",
+		c.Days, c.IncludeThreads, c.SelfActivity, keys(c.Activity.IgnoreSubtypes), keys(c.Activity.IgnoreUsers), strings.Join(rules, ","), unbounded)
+}
+
+// cachedActivity returns a channel's cached activity if it can be trusted: the entry
+// hasn't expired and its last activity isn't close enough to the cutoff to have
+// crossed it since
+func (c *Cleaner) cachedActivity(channelID string, cutoff time.Time) (ChannelActivity, bool) {
+	if c.cache == nil || c.ForceRescan {
+		return ChannelActivity{}, false
+	}
+	entry, ok := c.cache.Get(c.TeamID, c.cacheKey, channelID)
+	if !ok || time.Since(entry.FetchedAt) > c.CacheTTL {
+		return ChannelActivity{}, false
+	}
+
+	lastSeen := entry.LastSeen
+	if c.SelfActivity {
+		lastSeen = entry.MyLastSeen
+	}
+	if !lastSeen.IsZero() {
+		distance := lastSeen.Sub(cutoff)
+		if distance < 0 {
+			distance = -distance
+		}
+		if distance < c.CacheMargin {
+			return ChannelActivity{}, false
+		}
+	}
+	return ChannelActivity{LastSeen: entry.LastSeen, Source: entry.Source, MyLastSeen: entry.MyLastSeen}, true
+}
+
+// cacheActivity records a freshly checked channel in the scan cache
+func (c *Cleaner) cacheActivity(channelID string, activity ChannelActivity) {
+	if c.cache == nil {
+		return
+	}
+	c.cache.Put(c.TeamID, c.cacheKey, channelID, CacheEntry{
+		LastSeen:   activity.LastSeen,
+		Source:     activity.Source,
+		MyLastSeen: activity.MyLastSeen,
+		FetchedAt:  time.Now(),
+	})
+}
+
+// loadCache opens the scan cache for a scan; an unreadable cache is started afresh
+func (c *Cleaner) loadCache() {
+	c.cache = nil
+	if c.CachePath == "" {
+		return
+	}
+	cache, err := LoadScanCache(c.CachePath)
+	if err != nil {
+		if c.Verbose {
+			fmt.Fprintf(c.Out, "⚠️  Ignoring scan cache: %v\n", err)
+		}
+		cache = &ScanCache{Workspaces: make(map[string]*workspaceCache)}
+	}
+	c.cache = cache
+	c.cacheKey = c.cacheSettings()
+}
+
+// saveCache writes the scan cache back; a cache that can't be saved only costs a
+// slower next scan
+func (c *Cleaner) saveCache() {
+	if c.cache == nil {
+		return
+	}
+	if err := SaveScanCache(c.CachePath, c.cache, time.Now().Add(-c.CacheTTL)); err != nil && c.Verbose {
+		fmt.Fprintf(c.Out, "⚠️  Failed to save scan cache: %v\n", err)
+	}
+}
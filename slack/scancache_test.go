@@ -0,0 +1,150 @@
+package slack
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"workspace-channels-cleaner/slack/fakeapi"
+)
+
+// newCachedCleaner returns a test cleaner that keeps its scan cache at path
+func newCachedCleaner(t *testing.T, srv *fakeapi.Server, path string) *Cleaner {
+	t.Helper()
+	c := newTestCleaner(t, srv)
+	c.CachePath = path
+	c.CacheTTL = 24 * time.Hour
+	c.CacheMargin = 3 * 24 * time.Hour
+	return c
+}
+
+// historyCallsFor scans with c and returns how many history calls it made
+func historyCallsFor(t *testing.T, srv *fakeapi.Server, c *Cleaner) int {
+	t.Helper()
+	before := srv.Calls("conversations.history")
+	scanIDs(t, c)
+	return srv.Calls("conversations.history") - before
+}
+
+func TestScanCacheReusesFreshResults(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	srv.AddChannel(fakeapi.Channel("C1", "stale", false), fakeapi.Message("U1", daysAgo(90)))
+	srv.AddChannel(fakeapi.Channel("C2", "active", false), fakeapi.Message("U1", daysAgo(1)))
+	path := filepath.Join(t.TempDir(), "scancache.json")
+
+	if calls := historyCallsFor(t, srv, newCachedCleaner(t, srv, path)); calls != 2 {
+		t.Fatalf("first scan made %d history calls, want 2", calls)
+	}
+
+	// The cached verdict stands until the entry expires, even if the channel woke up
+	srv.Post("C1", fakeapi.Message("U1", daysAgo(0)))
+	c := newCachedCleaner(t, srv, path)
+	var progress ScanProgress
+	c.Progress = func(p ScanProgress) { progress = p }
+	before := srv.Calls("conversations.history")
+	if ids := scanIDs(t, c); len(ids) != 1 || ids[0] != "C1" {
+		t.Errorf("cached scan found %v, want the cached stale channel", ids)
+	}
+	if calls := srv.Calls("conversations.history") - before; calls != 0 || progress.Cached != 2 {
+		t.Errorf("cached scan made %d history calls with %d cached, want 0 and 2", calls, progress.Cached)
+	}
+
+	c = newCachedCleaner(t, srv, path)
+	c.ForceRescan = true
+	if ids := scanIDs(t, c); len(ids) != 0 {
+		t.Errorf("forced rescan found %v, want none", ids)
+	}
+	// A forced rescan refreshes the cache for the next scan
+	if calls := historyCallsFor(t, srv, newCachedCleaner(t, srv, path)); calls != 0 {
+		t.Errorf("scan after a forced rescan made %d history calls, want 0", calls)
+	}
+}
+
+func TestScanCacheExpiresEntries(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	srv.AddChannel(fakeapi.Channel("C1", "stale", false), fakeapi.Message("U1", daysAgo(90)))
+	path := filepath.Join(t.TempDir(), "scancache.json")
+	historyCallsFor(t, srv, newCachedCleaner(t, srv, path))
+
+	cache, err := LoadScanCache(path)
+	if err != nil {
+		t.Fatalf("LoadScanCache: %v", err)
+	}
+	entry, ok := cache.Get(fakeapi.TeamID, newCachedCleaner(t, srv, path).cacheSettings(), "C1")
+	if !ok {
+		t.Fatal("the scan cached nothing under the workspace's team ID")
+	}
+	entry.FetchedAt = time.Now().Add(-25 * time.Hour)
+	cache.Put(fakeapi.TeamID, newCachedCleaner(t, srv, path).cacheSettings(), "C1", entry)
+	if err := SaveScanCache(path, cache, time.Time{}); err != nil {
+		t.Fatalf("SaveScanCache: %v", err)
+	}
+
+	if calls := historyCallsFor(t, srv, newCachedCleaner(t, srv, path)); calls != 1 {
+		t.Errorf("scan after the TTL made %d history calls, want 1", calls)
+	}
+}
+
+func TestScanCacheRechecksChannelsNearTheCutoff(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	srv.AddChannel(fakeapi.Channel("C1", "far", false), fakeapi.Message("U1", daysAgo(90)))
+	srv.AddChannel(fakeapi.Channel("C2", "just-stale", false), fakeapi.Message("U1", daysAgo(31)))
+	srv.AddChannel(fakeapi.Channel("C3", "almost-stale", false), fakeapi.Message("U1", daysAgo(29)))
+	path := filepath.Join(t.TempDir(), "scancache.json")
+	historyCallsFor(t, srv, newCachedCleaner(t, srv, path))
+
+	if calls := historyCallsFor(t, srv, newCachedCleaner(t, srv, path)); calls != 2 {
+		t.Errorf("second scan made %d history calls, want 2 for the channels within the margin", calls)
+	}
+}
+
+func TestScanCacheIsDroppedWhenSettingsChange(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	srv.AddChannel(fakeapi.Channel("C1", "stale", false), fakeapi.Message("U1", daysAgo(90)))
+	srv.AddChannel(fakeapi.Channel("C2", "active", false), fakeapi.Message("U1", daysAgo(1)))
+	path := filepath.Join(t.TempDir(), "scancache.json")
+	historyCallsFor(t, srv, newCachedCleaner(t, srv, path))
+
+	for name, change := range map[string]func(*Cleaner){
+		"days":    func(c *Cleaner) { c.Days = 60; c.Cutoff = daysAgo(60) },
+		"threads": func(c *Cleaner) { c.IncludeThreads = true },
+		"ignored": func(c *Cleaner) { c.Activity = NewActivityFilter([]string{"bot_message"}, nil) },
+	} {
+		c := newCachedCleaner(t, srv, path)
+		change(c)
+		if calls := historyCallsFor(t, srv, c); calls != 2 {
+			t.Errorf("scan after changing %s made %d history calls, want 2", name, calls)
+		}
+		// Put the original settings back in the cache for the next change
+		historyCallsFor(t, srv, newCachedCleaner(t, srv, path))
+	}
+}
+
+func TestScanCacheIsKeptPerWorkspace(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	srv.AddChannel(fakeapi.Channel("C1", "stale", false), fakeapi.Message("U1", daysAgo(90)))
+	path := filepath.Join(t.TempDir(), "scancache.json")
+	historyCallsFor(t, srv, newCachedCleaner(t, srv, path))
+
+	other := newCachedCleaner(t, srv, path)
+	other.TeamID = "TOTHER"
+	if calls := historyCallsFor(t, srv, other); calls != 1 {
+		t.Errorf("scan of another workspace made %d history calls, want 1", calls)
+	}
+	if calls := historyCallsFor(t, srv, newCachedCleaner(t, srv, path)); calls != 0 {
+		t.Errorf("scan of the first workspace made %d history calls, want its entries kept", calls)
+	}
+
+	cache, err := LoadScanCache(path)
+	if err != nil {
+		t.Fatalf("LoadScanCache: %v", err)
+	}
+	if len(cache.Workspaces) != 2 {
+		t.Errorf("cache holds %d workspaces, want 2", len(cache.Workspaces))
+	}
+}
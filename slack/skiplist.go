@@ -0,0 +1,261 @@
+package slack
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultSkipListPath is where the skip list is stored
+const DefaultSkipListPath = "config/skiplist.json"
+
+// SkipEntry is a single skip list pattern with optional bookkeeping.
+// In the file it is either a plain string (legacy format) or an object:
+//
+//	{"name": "general", "reason": "company-wide", "added_by": "ashfaq",
+//	 "added_at": "2024-12-19T00:00:00Z", "expires_at": "2025-06-30T00:00:00Z"}
+//
+// "pattern" may be used instead of "name" for globs, regexes and IDs.
+type SkipEntry struct {
+	Pattern   string     `json:"pattern"`
+	Reason    string     `json:"reason,omitempty"`
+	AddedBy   string     `json:"added_by,omitempty"`
+	AddedAt   *time.Time `json:"added_at,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+
+	pattern *Pattern
+}
+
+// skipEntryJSON mirrors SkipEntry for decoding, accepting "name" as an alias of "pattern"
+type skipEntryJSON struct {
+	Name      string     `json:"name"`
+	Pattern   string     `json:"pattern"`
+	Reason    string     `json:"reason"`
+	AddedBy   string     `json:"added_by"`
+	AddedAt   *time.Time `json:"added_at"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// UnmarshalJSON accepts both the legacy string form and the object form
+func (e *SkipEntry) UnmarshalJSON(data []byte) error {
+	var legacy string
+	if err := json.Unmarshal(data, &legacy); err == nil {
+		*e = SkipEntry{Pattern: legacy}
+		return nil
+	}
+
+	var obj skipEntryJSON
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	pattern := obj.Pattern
+	if pattern == "" {
+		pattern = obj.Name
+	}
+	*e = SkipEntry{
+		Pattern:   pattern,
+		Reason:    obj.Reason,
+		AddedBy:   obj.AddedBy,
+		AddedAt:   obj.AddedAt,
+		ExpiresAt: obj.ExpiresAt,
+	}
+	return nil
+}
+
+// MarshalJSON writes entries without bookkeeping in the legacy string form
+func (e SkipEntry) MarshalJSON() ([]byte, error) {
+	if e.Reason == "" && e.AddedBy == "" && e.AddedAt == nil && e.ExpiresAt == nil {
+		return json.Marshal(e.Pattern)
+	}
+
+	type plain SkipEntry // Drops the MarshalJSON method
+	return json.Marshal(plain(e))
+}
+
+// Expired reports whether the entry has stopped protecting channels
+func (e SkipEntry) Expired(now time.Time) bool {
+	return e.ExpiresAt != nil && !now.Before(*e.ExpiresAt)
+}
+
+// SkipList holds the patterns of channels that must never be processed
+type SkipList struct {
+	entries []SkipEntry
+}
+
+// ProtectedChannel is a channel the skip list kept out of a scan
+type ProtectedChannel struct {
+	ID      string
+	Name    string
+	Pattern string
+}
+
+// LoadSkipList loads the skip list from a JSON file
+func LoadSkipList(path string) (*SkipList, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return &SkipList{}, nil // Return empty list if file doesn't exist
+	}
+
+	var list []SkipEntry
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse skip list: %w", err)
+	}
+
+	skipList := &SkipList{}
+	for _, e := range list {
+		if err := skipList.AddEntry(e); err != nil {
+			return nil, fmt.Errorf("invalid skip list entry: %w", err)
+		}
+	}
+	return skipList, nil
+}
+
+// SaveSkipList saves the skip list to a JSON file
+func SaveSkipList(path string, skipList *SkipList) error {
+	data, err := json.MarshalIndent(skipList.Entries(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal skip list: %w", err)
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
+// Add appends a plain pattern; adding a pattern that is already present is a no-op
+func (l *SkipList) Add(raw string) error {
+	p, err := ParsePattern(raw)
+	if err != nil {
+		return err
+	}
+	if _, ok := l.Entry(p.String()); ok {
+		return nil
+	}
+	return l.AddEntry(SkipEntry{Pattern: raw})
+}
+
+// AddEntry appends an entry. Adding a pattern that is already present replaces its
+// reason and expiry but keeps who added it and when.
+func (l *SkipList) AddEntry(e SkipEntry) error {
+	p, err := ParsePattern(e.Pattern)
+	if err != nil {
+		return err
+	}
+	e.Pattern = p.String()
+	e.pattern = p
+	for i := range l.entries {
+		if l.entries[i].Pattern == e.Pattern {
+			l.entries[i].Reason = e.Reason
+			l.entries[i].ExpiresAt = e.ExpiresAt
+			return nil
+		}
+	}
+	l.entries = append(l.entries, e)
+	return nil
+}
+
+// Remove deletes a pattern by its text
+func (l *SkipList) Remove(raw string) {
+	for i, e := range l.entries {
+		if e.Pattern == raw {
+			l.entries = append(l.entries[:i], l.entries[i+1:]...)
+			return
+		}
+	}
+}
+
+// Renew moves the expiry of a pattern to until
+func (l *SkipList) Renew(raw string, until time.Time) {
+	for i := range l.entries {
+		if l.entries[i].Pattern == raw {
+			l.entries[i].ExpiresAt = &until
+			return
+		}
+	}
+}
+
+// Entries returns all entries in file order, including expired ones
+func (l *SkipList) Entries() []SkipEntry {
+	list := []SkipEntry{}
+	if l == nil {
+		return list
+	}
+	return append(list, l.entries...)
+}
+
+// Entry looks up an entry by its pattern text
+func (l *SkipList) Entry(raw string) (SkipEntry, bool) {
+	if l != nil {
+		for _, e := range l.entries {
+			if e.Pattern == raw {
+				return e, true
+			}
+		}
+	}
+	return SkipEntry{}, false
+}
+
+// Patterns returns the patterns in file order, including expired ones
+func (l *SkipList) Patterns() []string {
+	list := []string{}
+	for _, e := range l.Entries() {
+		list = append(list, e.Pattern)
+	}
+	return list
+}
+
+// Expired returns the entries that no longer protect channels
+func (l *SkipList) Expired(now time.Time) []SkipEntry {
+	var expired []SkipEntry
+	for _, e := range l.Entries() {
+		if e.Expired(now) {
+			expired = append(expired, e)
+		}
+	}
+	return expired
+}
+
+// Match returns the first unexpired pattern protecting the channel, if any
+func (l *SkipList) Match(id, name string) (string, bool) {
+	if l == nil {
+		return "", false
+	}
+	now := time.Now()
+	for _, e := range l.entries {
+		if e.Expired(now) {
+			continue
+		}
+		if e.pattern.Match(id, name) {
+			return e.Pattern, true
+		}
+	}
+	return "", false
+}
+
+// SkipListErr returns why the skip list couldn't be loaded when the cleaner was
+// created. Scans and actions refuse to run without it rather than run unprotected.
+func (c *Cleaner) SkipListErr() error {
+	if c.skipListErr == nil {
+		return nil
+	}
+	return fmt.Errorf("skip list can't be used, so no channel would be protected: %w", c.skipListErr)
+}
+
+// failAll reports every channel as failed with err
+func failAll(action Action, channels []ChannelInfo, err error) *LeaveReport {
+	report := &LeaveReport{Action: action}
+	for _, ch := range channels {
+		report.Failed = append(report.Failed, LeaveFailure{Channel: ch, Err: err})
+	}
+	return report
+}
+
+// CurrentUser returns the local user name recorded as added_by
+func CurrentUser() string {
+	for _, key := range []string{"USER", "USERNAME"} {
+		if name := strings.TrimSpace(os.Getenv(key)); name != "" {
+			return name
+		}
+	}
+	return ""
+}
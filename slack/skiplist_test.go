@@ -0,0 +1,201 @@
+package slack
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"workspace-channels-cleaner/slack/fakeapi"
+)
+
+func TestParsePattern(t *testing.T) {
+	tests := []struct {
+		raw     string
+		wantErr bool
+		want    string // String() of the parsed pattern
+	}{
+		{raw: "general", want: "general"},
+		{raw: "  #general ", want: "#general"},
+		{raw: "team-*", want: "team-*"},
+		{raw: `re:^inc-\d+$`, want: `re:^inc-\d+$`},
+		{raw: "id:C0123", want: "id:C0123"},
+		{raw: "", wantErr: true},
+		{raw: "   ", wantErr: true},
+		{raw: "id:", wantErr: true},
+		{raw: "re:inc-(", wantErr: true},
+		{raw: "team-[", wantErr: true},
+	}
+	for _, tt := range tests {
+		p, err := ParsePattern(tt.raw)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParsePattern(%q) error = %v, want error %t", tt.raw, err, tt.wantErr)
+			continue
+		}
+		if err == nil && p.String() != tt.want {
+			t.Errorf("ParsePattern(%q).String() = %q, want %q", tt.raw, p.String(), tt.want)
+		}
+	}
+}
+
+func TestSkipListMatch(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	l := &SkipList{}
+	for _, e := range []SkipEntry{
+		{Pattern: "#general"},
+		{Pattern: "team-*"},
+		{Pattern: `re:^inc-\d+$`},
+		{Pattern: "id:C0123"},
+		{Pattern: "old-project", ExpiresAt: &past},
+	} {
+		if err := l.AddEntry(e); err != nil {
+			t.Fatalf("AddEntry(%q): %v", e.Pattern, err)
+		}
+	}
+
+	tests := []struct {
+		id, name string
+		want     string // The matching pattern, empty for none
+	}{
+		{"C1", "general", "#general"},
+		{"C1", "general-chat", ""},
+		{"C1", "team-design", "team-*"},
+		{"C1", "my-team-design", ""},
+		{"C1", "inc-42", `re:^inc-\d+$`},
+		{"C1", "inc-42-followup", ""},
+		{"C0123", "renamed", "id:C0123"},
+		{"C01234", "renamed", ""},
+		{"C1", "old-project", ""}, // Expired
+		{"C1", "random", ""},
+	}
+	for _, tt := range tests {
+		got, ok := l.Match(tt.id, tt.name)
+		if ok != (tt.want != "") || got != tt.want {
+			t.Errorf("Match(%q, %q) = %q, %t, want %q", tt.id, tt.name, got, ok, tt.want)
+		}
+	}
+
+	var none *SkipList
+	if _, ok := none.Match("C1", "general"); ok {
+		t.Error("a nil skip list matched a channel")
+	}
+}
+
+func TestInvalidSkipListStopsScansAndActions(t *testing.T) {
+	srv := fakeapi.New()
+	defer srv.Close()
+	srv.AddChannel(fakeapi.Channel("C1", "old", false), fakeapi.Message("U1", time.Now().AddDate(0, 0, -90)))
+
+	t.Chdir(t.TempDir())
+	if err := os.Mkdir("config", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(DefaultSkipListPath, []byte(`["general", "re:inc-("]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewCleanerWithAPI(srv.Client(), 100, []string{"public_channel"}, 30, false)
+	c.Limiter = NewLimiter(fastRates)
+	if _, err := c.GetFilteredChannels(t.Context()); err == nil || !strings.Contains(err.Error(), "inc-(") {
+		t.Errorf("scan with an invalid skip list returned %v, want the bad entry", err)
+	}
+	for _, action := range []Action{ActionLeave, ActionArchive, ActionMute, ActionNotice} {
+		report := c.Apply(action, []ChannelInfo{{ID: "C1", Name: "old", Type: "public"}})
+		if len(report.Failed) != 1 || len(report.Succeeded) != 0 {
+			t.Errorf("%s with an invalid skip list reported %+v, want the channel failed", action, report)
+		}
+	}
+	if !srv.IsMember("C1") || srv.IsArchived("C1") || srv.IsMuted("C1") || len(srv.Messages("C1")) != 1 {
+		t.Error("an action ran although the skip list couldn't be loaded")
+	}
+}
+
+func TestLoadSkipListMixesStringsAndObjects(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "skiplist.json")
+	data := `["general", {"pattern": "team-*", "reason": "teams"}, {"name": "random", "added_by": "ada", "expires_at": "2030-01-01T00:00:00Z"}]`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := LoadSkipList(path)
+	if err != nil {
+		t.Fatalf("LoadSkipList: %v", err)
+	}
+	entries := list.Entries()
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	if e := entries[0]; e.Pattern != "general" || e.Reason != "" || e.ExpiresAt != nil {
+		t.Errorf("legacy entry is %+v, want a plain pattern", e)
+	}
+	if e := entries[1]; e.Pattern != "team-*" || e.Reason != "teams" {
+		t.Errorf("object entry is %+v, want team-* with its reason", e)
+	}
+	// "name" is an alias of "pattern"
+	if e := entries[2]; e.Pattern != "random" || e.AddedBy != "ada" || e.ExpiresAt == nil || e.ExpiresAt.Year() != 2030 {
+		t.Errorf("name entry is %+v, want random added by ada expiring in 2030", e)
+	}
+	if pattern, ok := list.Match("C1", "team-web"); !ok || pattern != "team-*" {
+		t.Errorf("team-web matched %q (%t), want team-*", pattern, ok)
+	}
+}
+
+func TestSaveSkipListKeepsPlainEntriesAsStrings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "skiplist.json")
+	expires := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	list := &SkipList{}
+	if err := list.Add("general"); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.AddEntry(SkipEntry{Pattern: "re:^inc-", Reason: "incidents", AddedBy: "ada", ExpiresAt: &expires}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveSkipList(path, list); err != nil {
+		t.Fatalf("SaveSkipList: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"general"`) || strings.Contains(string(data), `"pattern": "general"`) {
+		t.Errorf("saved %s, want general as a plain string", data)
+	}
+
+	loaded, err := LoadSkipList(path)
+	if err != nil {
+		t.Fatalf("LoadSkipList: %v", err)
+	}
+	entries := loaded.Entries()
+	if len(entries) != 2 || entries[0].Pattern != "general" {
+		t.Fatalf("loaded %+v, want general and re:^inc-", entries)
+	}
+	e := entries[1]
+	if e.Pattern != "re:^inc-" || e.Reason != "incidents" || e.AddedBy != "ada" || e.ExpiresAt == nil || !e.ExpiresAt.Equal(expires) {
+		t.Errorf("loaded %+v, want the reason, added_by and expiry kept", e)
+	}
+}
+
+func TestReAddingASkipEntryUpdatesReasonAndExpiry(t *testing.T) {
+	expires := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	list := &SkipList{}
+	if err := list.AddEntry(SkipEntry{Pattern: "team-*", Reason: "old reason", AddedBy: "ada"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.AddEntry(SkipEntry{Pattern: "team-*", Reason: "new reason", AddedBy: "bob", ExpiresAt: &expires}); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.Add("team-*"); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := list.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("got %+v, want a single team-* entry", entries)
+	}
+	e := entries[0]
+	if e.Reason != "new reason" || e.ExpiresAt == nil || !e.ExpiresAt.Equal(expires) || e.AddedBy != "ada" {
+		t.Errorf("got %+v, want the new reason and expiry with the original added_by", e)
+	}
+}
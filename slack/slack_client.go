@@ -1,236 +1,438 @@
 package slack
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
+	"io"
 	"os"
-	"strconv"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/slack-go/slack"
+
+	"workspace-channels-cleaner/config"
+	"workspace-channels-cleaner/policy"
 )
 
 type ChannelInfo struct {
-	ID       string
-	Name     string
-	LastSeen time.Time
-	Type     string
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	LastSeen time.Time `json:"last_seen"`
+	Type     string    `json:"type"`
+	Created  time.Time `json:"created"`
+
+	// ActivitySource tells whether LastSeen came from a message or a thread reply
+	ActivitySource string `json:"activity_source,omitempty"`
+
+	// MyLastSeen is the authenticated user's last post or reaction, set when scanning
+	// by the user's own activity. The zero time means none since the cutoff.
+	MyLastSeen time.Time `json:"my_last_seen,omitzero"`
+
+	// IsGeneral marks the workspace's general channel, which can't be archived
+	IsGeneral bool `json:"is_general,omitempty"`
+
+	// IsShared marks a channel shared with another workspace; IsExtShared one
+	// shared with another organization
+	IsShared    bool `json:"is_shared,omitempty"`
+	IsExtShared bool `json:"is_ext_shared,omitempty"`
+
+	// NumMembers is the channel's member count
+	NumMembers int `json:"num_members,omitempty"`
+
+	// Creator is the ID of the user who created the channel
+	Creator string `json:"creator,omitempty"`
+
+	Topic   string `json:"topic,omitempty"`
+	Purpose string `json:"purpose,omitempty"`
+
+	// TopPosters lists who posted most; only known when scanning a workspace export
+	TopPosters []PosterCount `json:"top_posters,omitempty"`
+
+	// Muted marks a channel the cleaner muted earlier that is still stale
+	Muted bool `json:"muted,omitempty"`
+
+	// Rule is the rule that decided the channel's threshold and action; nil when
+	// none matched and the global days applied
+	Rule *RuleMatch `json:"rule,omitempty"`
+
+	// Error explains why the channel's activity could not be determined; such
+	// channels are reported so they aren't silently missed
+	Error string `json:"error,omitempty"`
+}
+
+// Undetermined reports whether the scan failed to check the channel
+func (ch ChannelInfo) Undetermined() bool {
+	return ch.Error != ""
 }
 
 type Cleaner struct {
-	API          *slack.Client
-	SkipChannels map[string]bool
-	Limit        int
-	Types        []string
-	Cutoff       time.Time
-	Keyword      string
-	Verbose      bool
+	API            WorkspaceAPI
+	SkipList       *SkipList
+	Limit          int
+	Types          []string
+	Days           int
+	Cutoff         time.Time
+	Include        []string // Name filter entries a channel must match one of; see NameFilter
+	Exclude        []string // Name filter entries that leave a channel out
+	Verbose        bool
+	Activity       ActivityFilter // Which messages count as activity; the zero value counts every message
+	IncludeEmpty   bool           // Report channels without any message that counts as activity
+	IncludeThreads bool           // Consider thread replies on recent parents as activity
+	SelfActivity   bool           // Judge staleness by the authenticated user's own posts and reactions
+	Limiter        *Limiter       // Paces API calls; shared by every cleaner unless replaced, nil for none
+	UserID         string         // The authenticated user; looked up via auth.test when empty
+	TeamID         string         // The workspace the scan cache is keyed by; looked up like UserID
+	Out            io.Writer      // Destination for verbose output
+	JournalPath    string         // Where successful leaves are recorded; empty disables the journal
+	NoticesPath    string         // Where posted pre-archive notices are recorded
+	MutedPath      string         // Where channels muted by the cleaner are recorded
+	ExportDir      string         // When set, channels are exported here before they are left
+	NoticeMessage  string         // text/template for pre-archive notices, rendered with NoticeData
+	GraceDays      int            // Days between a notice and archiving its channel
+
+	// Rules set the threshold and action per channel, first match wins. They are
+	// parsed when a scan starts.
+	Rules []config.RuleConfig
+
+	// Policy, when set, decides which channels are stale instead of Days and the
+	// rules' thresholds; see the policy package. It is parsed when a scan starts.
+	Policy string
+
+	// Protected lists the channels the skip list kept out of the last scan
+	Protected []ProtectedChannel
+
+	// CachePath, when set, keeps scan results between runs. Cached results are reused
+	// for CacheTTL unless their last activity is within CacheMargin of the cutoff.
+	// ForceRescan ignores cached results but still refreshes the cache.
+	CachePath   string
+	CacheTTL    time.Duration
+	CacheMargin time.Duration
+	ForceRescan bool
+	cache       *ScanCache
+	cacheKey    string
+	skipListErr error           // Why the skip list file couldn't be loaded
+	muted       map[string]bool // Channels recorded in MutedPath, read at the start of a scan
+	rules       []Rule          // Rules, parsed
+	names       *NameFilter     // Include and Exclude, parsed
+	policy      *policy.Expr    // Policy, parsed; nil when empty
+
+	// Found, when set, receives each stale channel as soon as it is found, before the
+	// final sort. Calls never overlap.
+	Found func(ChannelInfo)
+
+	// Progress, when set, receives a snapshot each time a scan makes progress
+	Progress   func(ScanProgress)
+	progress   ScanProgress
+	progressMu sync.Mutex
 }
 
 // NewCleaner creates a new Slack cleaner instance
-func NewCleaner(token string, limit int, types []string, days int, keyword string, verbose bool) *Cleaner {
-	api := slack.New(token)
-	skipChannels, _ := LoadSkipList("config/skiplist.json")
+func NewCleaner(token string, limit int, types []string, days int, verbose bool) *Cleaner {
+	var options []slack.Option
+	if url := config.GetWorkspaceAPIURL(); url != "" {
+		options = append(options, slack.OptionAPIURL(url))
+	}
+	return NewCleanerWithAPI(slack.New(token, options...), limit, types, days, verbose)
+}
+
+// NewCleanerWithAPI creates a cleaner that talks to the given API implementation
+func NewCleanerWithAPI(api WorkspaceAPI, limit int, types []string, days int, verbose bool) *Cleaner {
+	skipList, skipListErr := LoadSkipList(DefaultSkipListPath)
+	if skipListErr != nil {
+		skipListErr = fmt.Errorf("%s: %w", DefaultSkipListPath, skipListErr)
+	}
 	
 	cutoff := time.Now().AddDate(0, 0, -days)
 	
 	return &Cleaner{
-		API:          api,
-		SkipChannels: skipChannels,
-		Limit:        limit,
-		Types:        types,
-		Cutoff:       cutoff,
-		Keyword:      keyword,
-		Verbose:      verbose,
+		API:           api,
+		SkipList:      skipList,
+		skipListErr:   skipListErr,
+		Limit:         limit,
+		Types:         types,
+		Days:          days,
+		Cutoff:        cutoff,
+		Verbose:       verbose,
+		Out:           os.Stdout,
+		JournalPath:   DefaultJournalPath,
+		NoticesPath:   DefaultNoticesPath,
+		MutedPath:     DefaultMutedPath,
+		NoticeMessage: config.DefaultNoticeMessage,
+		GraceDays:     14,
+		Limiter:       DefaultLimiter,
 	}
 }
 
-// LoadSkipList loads the skip list from a JSON file
-func LoadSkipList(path string) (map[string]bool, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return make(map[string]bool), nil // Return empty map if file doesn't exist
+// scanWorkers is how many channels are checked at once; the limiter may allow fewer
+// requests in flight after a rate limit
+const scanWorkers = 5
+
+// GetFilteredChannels retrieves and filters channels based on criteria.
+// Every channel that is checked ends up stale, active, left out by the policy or
+// errored; stale and errored channels are returned, the latter with Error set. Cancelling ctx stops the scan;
+// it then returns the context's error once every worker has finished.
+func (c *Cleaner) GetFilteredChannels(ctx context.Context) ([]ChannelInfo, error) {
+	c.Protected = nil
+	c.updateProgress(func(p *ScanProgress) { *p = ScanProgress{} })
+	if err := c.SkipListErr(); err != nil {
+		return nil, err
 	}
-	
-	var list []string
-	if err := json.Unmarshal(data, &list); err != nil {
-		return nil, fmt.Errorf("failed to parse skip list: %w", err)
+	if err := c.parseRules(); err != nil {
+		return nil, err
 	}
-	
-	skipMap := make(map[string]bool)
-	for _, name := range list {
-		skipMap[strings.TrimSpace(name)] = true
+	if err := c.parsePolicy(); err != nil {
+		return nil, err
+	}
+	if err := c.parseNames(); err != nil {
+		return nil, err
 	}
-	return skipMap, nil
-}
 
-// SaveSkipList saves the skip list to a JSON file
-func SaveSkipList(path string, skipList map[string]bool) error {
-	var list []string
-	for name := range skipList {
-		list = append(list, name)
+	// Workers only read the cleaner, so resolve the user before any of them starts
+	if err := c.resolveIdentity(ctx); err != nil {
+		return nil, err
 	}
-	
-	data, err := json.MarshalIndent(list, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal skip list: %w", err)
+	c.loadCache()
+	c.loadMuted()
+
+	// Stop the workers too if listing fails part way
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan slack.Channel, c.Limit)
+	outcomes := make(chan ChannelOutcome)
+	var workers sync.WaitGroup
+	for i := 0; i < scanWorkers; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for ch := range jobs {
+				outcomes <- c.checkOutcome(ctx, ch)
+			}
+		}()
 	}
-	
-	return os.WriteFile(path, data, 0644)
-}
+	go func() {
+		workers.Wait()
+		close(outcomes)
+	}()
 
-// GetFilteredChannels retrieves and filters channels based on criteria
-func (c *Cleaner) GetFilteredChannels() ([]ChannelInfo, error) {
+	// A single collector owns the results, so workers share nothing but channels
 	var results []ChannelInfo
-	var wg sync.WaitGroup
-	chMutex := sync.Mutex{}
-	semaphore := make(chan struct{}, 5)
-	cursor := ""
+	collected := make(chan struct{})
+	go func() {
+		defer close(collected)
+		for o := range outcomes {
+			if o.Outcome == OutcomeError && ctx.Err() != nil {
+				continue // Cancelled, not broken
+			}
+			c.updateProgress(func(p *ScanProgress) {
+				p.Checked++
+				switch o.Outcome {
+				case OutcomeStale:
+					p.Matched++
+				case OutcomeError:
+					p.Errors++
+				}
+				if o.Cached {
+					p.Cached++
+				}
+			})
+			if o.Outcome == OutcomeActive || o.Outcome == OutcomeExcluded {
+				continue
+			}
+			results = append(results, o.Channel)
+			if c.Found != nil {
+				c.Found(o.Channel)
+			}
+		}
+	}()
+
+	listErr := c.listChannels(ctx, jobs)
+	if listErr != nil {
+		cancel()
+	}
+	close(jobs)
+	<-collected
+	c.saveCache() // Even a cancelled scan leaves valid entries behind
+	if listErr != nil {
+		return nil, listErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if c.Verbose && c.Limiter != nil {
+		fmt.Fprintf(c.Out, "📈 Effective rates: %s\n", c.Limiter.Summary())
+	}
+	if c.SelfActivity {
+		SortChannelsBy(results, func(ch ChannelInfo) time.Time { return ch.MyLastSeen })
+	} else {
+		SortChannels(results)
+	}
+	return results, nil
+}
 
+// listChannels pages through the channel list and queues every channel that should
+// be checked. Skip-listed channels are recorded in Protected instead.
+func (c *Cleaner) listChannels(ctx context.Context, jobs chan<- slack.Channel) error {
+	cursor := ""
 	for {
 		var channels []slack.Channel
 		var nextCursor string
-		var err error
 
-		for {
-			params := &slack.GetConversationsParameters{
+		err := c.call(ctx, "conversations.list", func() (err error) {
+			channels, nextCursor, err = c.API.GetConversationsContext(ctx, &slack.GetConversationsParameters{
 				Limit:           c.Limit,
 				ExcludeArchived: true,
 				Cursor:          cursor,
 				Types:           c.Types,
-			}
-
-			channels, nextCursor, err = c.API.GetConversations(params)
-			if err != nil {
-				if rateErr := c.handleRateLimit(err); rateErr != nil {
-					return nil, rateErr
-				}
-				continue
-			}
-			break
+			})
+			return err
+		})
+		if err != nil {
+			return err
 		}
+		c.updateProgress(func(p *ScanProgress) { p.Pages++ })
 
 		for _, ch := range channels {
-			if !ch.IsMember || c.SkipChannels[ch.Name] {
+			if !ch.IsMember {
 				continue
 			}
-			if c.Keyword != "" && !strings.Contains(ch.Name, c.Keyword) {
+			if pattern, ok := c.SkipList.Match(ch.ID, ch.Name); ok {
+				c.Protected = append(c.Protected, ProtectedChannel{ID: ch.ID, Name: ch.Name, Pattern: pattern})
+				continue
+			}
+			if !c.names.Allows(ch.ID, ch.Name) {
+				continue
+			}
+			if rule := c.matchRule(ch.ID, ch.Name, channelType(ch)); rule != nil && rule.Action == ActionIgnore {
 				continue
 			}
 
-			wg.Add(1)
-			go func(ch slack.Channel) {
-				defer wg.Done()
-				semaphore <- struct{}{}
-				defer func() { <-semaphore }()
-
-				var lastTime time.Time
-				var history *slack.GetConversationHistoryResponse
-				
-				for attempt := 0; attempt < 2; attempt++ {
-					history, err = c.API.GetConversationHistory(&slack.GetConversationHistoryParameters{
-						ChannelID: ch.ID,
-						Limit:     1,
-					})
-					if err != nil {
-						if rateErr := c.handleRateLimit(err); rateErr != nil {
-							return
-						}
-						continue
-					}
-					break
-				}
-				
-				if history == nil || len(history.Messages) == 0 {
-					return
-				}
-
-				tsFloat, err := strconv.ParseFloat(history.Messages[0].Timestamp, 64)
-				if err != nil {
-					return
-				}
-				lastTime = time.Unix(int64(tsFloat), 0)
-				
-				// Only include if older than cutoff
-				if !lastTime.Before(c.Cutoff) {
-					return
-				}
-
-				chMutex.Lock()
-				channelType := "public"
-				if ch.IsPrivate {
-					channelType = "private"
-				}
-				results = append(results, ChannelInfo{
-					ID:       ch.ID,
-					Name:     ch.Name,
-					LastSeen: lastTime,
-					Type:     channelType,
-				})
-				chMutex.Unlock()
-				time.Sleep(1 * time.Second)
-			}(ch)
+			c.updateProgress(func(p *ScanProgress) { p.Total++ })
+			select {
+			case jobs <- ch:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
 		}
 
 		if nextCursor == "" {
-			break
+			c.updateProgress(func(p *ScanProgress) { p.Listed = true })
+			return nil
 		}
 		cursor = nextCursor
 	}
+}
 
-	wg.Wait()
-	return results, nil
+// SortChannels orders channels from most to least obviously stale: channels without
+// any activity first, oldest created first, then the rest by last activity
+func SortChannels(channels []ChannelInfo) {
+	SortChannelsBy(channels, func(ch ChannelInfo) time.Time { return ch.LastSeen })
+}
+
+// SortChannelsBy is SortChannels with a different timestamp deciding staleness.
+// Channels that could not be checked go last.
+func SortChannelsBy(channels []ChannelInfo, lastSeen func(ChannelInfo) time.Time) {
+	sort.SliceStable(channels, func(i, j int) bool {
+		if channels[i].Undetermined() != channels[j].Undetermined() {
+			return channels[j].Undetermined()
+		}
+		a, b := lastSeen(channels[i]), lastSeen(channels[j])
+		if a.IsZero() != b.IsZero() {
+			return a.IsZero()
+		}
+		if a.IsZero() {
+			return channels[i].Created.Before(channels[j].Created)
+		}
+		return a.Before(b)
+	})
 }
 
-// LeaveChannels leaves the specified channels
-func (c *Cleaner) LeaveChannels(channels []ChannelInfo) error {
+// LeaveChannels tries to leave every given channel, retrying rate-limited calls,
+// and reports the outcome for each channel. Channels whose activity the scan couldn't
+// determine are skipped. Channels whose rule says to mute them are muted instead and
+// listed in the report's Muted.
+func (c *Cleaner) LeaveChannels(channels []ChannelInfo) *LeaveReport {
+	if err := c.SkipListErr(); err != nil {
+		return failAll(ActionLeave, channels, err)
+	}
+	report := &LeaveReport{Action: ActionLeave}
+	channels, toMute := splitRuleMutes(channels)
+	exportDir := c.exportDir()
 	for i, ch := range channels {
 		if c.Verbose {
-			fmt.Printf("➡️  [%d/%d] Leaving #%s (ID: %s)...\n", i+1, len(channels), ch.Name, ch.ID)
+			fmt.Fprintf(c.Out, "➡️  [%d/%d] Leaving #%s (ID: %s)...\n", i+1, len(channels), ch.Name, ch.ID)
 		}
 		
-		_, err := c.API.LeaveConversation(ch.ID)
+		if reason, ok := c.skipReason(ch, false); ok {
+			report.Skipped = append(report.Skipped, LeaveSkip{Channel: ch, Reason: reason})
+			continue
+		}
+		
+		// Without a copy of the history there is no leaving: it may be gone for good
+		if c.ExportDir != "" {
+			path, err := c.ExportChannel(context.Background(), exportDir, ch)
+			if err != nil {
+				if c.Verbose {
+					fmt.Fprintf(c.Out, "❌ Failed to export #%s, not leaving it: %v\n", ch.Name, err)
+				}
+				report.Failed = append(report.Failed, LeaveFailure{Channel: ch, Err: fmt.Errorf("export failed, channel not left: %w", err)})
+				continue
+			}
+			if c.Verbose {
+				fmt.Fprintf(c.Out, "💾 Exported #%s to %s\n", ch.Name, path)
+			}
+		}
+		
+		notInChannel, err := c.leave(ch.ID)
 		if err != nil {
-			if rateErr := c.handleRateLimit(err); rateErr != nil {
-				return fmt.Errorf("failed to leave #%s: %w", ch.Name, rateErr)
+			if c.Verbose {
+				fmt.Fprintf(c.Out, "❌ Failed to leave #%s: %v\n", ch.Name, err)
 			}
-			return fmt.Errorf("failed to leave #%s: %w", ch.Name, err)
+			report.Failed = append(report.Failed, LeaveFailure{Channel: ch, Err: err})
+			continue
+		}
+		if notInChannel {
+			report.Skipped = append(report.Skipped, LeaveSkip{Channel: ch, Reason: "not a member anymore"})
+			continue
 		}
 		
-		if c.Verbose {
-			fmt.Printf("✅ Left #%s\n", ch.Name)
+		report.Succeeded = append(report.Succeeded, ch)
+		if err := c.recordLeave(ch); err != nil {
+			report.JournalErr = fmt.Errorf("left #%s but failed to record it in the journal: %w", ch.Name, err)
 		}
 		
-		time.Sleep(1 * time.Second)
-	}
-	return nil
-}
-
-
-func (c *Cleaner) handleRateLimit(err error) error {
-	if strings.Contains(err.Error(), "rate_limited") {
 		if c.Verbose {
-			fmt.Println("⚠️  Hit rate limit. Waiting 30s...")
+			fmt.Fprintf(c.Out, "✅ Left #%s\n", ch.Name)
 		}
-		time.Sleep(30 * time.Second)
-		return nil
 	}
 	
-	if rateErr, ok := err.(*slack.RateLimitedError); ok {
-		wait := time.Duration(rateErr.RetryAfter) * time.Second
-		if wait <= 0 {
-			wait = 30 * time.Second
-		}
-		if c.Verbose {
-			fmt.Printf("⏳ Rate limit hit. Waiting %v before retrying...\n", wait)
-		}
-		time.Sleep(wait)
-		return nil
+	// A channel that was left doesn't need to stay on the muted list
+	if err := c.forgetMuted(report.Succeeded); err != nil && c.Verbose {
+		fmt.Fprintf(c.Out, "⚠️  Failed to update muted channels: %v\n", err)
 	}
 	
-	return err
+	if len(toMute) > 0 {
+		muted := c.MuteChannels(toMute)
+		report.Muted = muted.Succeeded
+		report.Failed = append(report.Failed, muted.Failed...)
+		report.Skipped = append(report.Skipped, muted.Skipped...)
+		if report.JournalErr == nil {
+			report.JournalErr = muted.JournalErr
+		}
+	}
+	return report
+}
+
+// leave leaves a single channel, retrying when rate limited
+func (c *Cleaner) leave(channelID string) (bool, error) {
+	var notInChannel bool
+	err := c.call(context.Background(), "conversations.leave", func() (err error) {
+		notInChannel, err = c.API.LeaveConversation(channelID)
+		return err
+	})
+	return notInChannel, err
 }
 
 // GetChannelTypes converts user-friendly types to workspace API types
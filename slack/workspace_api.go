@@ -0,0 +1,24 @@
+package slack
+
+import (
+	"context"
+
+	"github.com/slack-go/slack"
+)
+
+// WorkspaceAPI is the subset of the workspace API used by the Cleaner.
+// *slack.Client satisfies it; tests can point a client at fakeapi.Server.
+// Calls made while scanning take a context so a scan can be cancelled.
+type WorkspaceAPI interface {
+	GetConversationsContext(ctx context.Context, params *slack.GetConversationsParameters) ([]slack.Channel, string, error)
+	GetConversationHistoryContext(ctx context.Context, params *slack.GetConversationHistoryParameters) (*slack.GetConversationHistoryResponse, error)
+	AuthTestContext(ctx context.Context) (*slack.AuthTestResponse, error)
+	LeaveConversation(channelID string) (bool, error)
+	JoinConversation(channelID string) (*slack.Channel, string, []string, error)
+	ArchiveConversation(channelID string) error
+	PostMessageContext(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error)
+	MuteChat(channelID string) (*slack.UserPrefsCarrier, error)
+	GetConversationRepliesContext(ctx context.Context, params *slack.GetConversationRepliesParameters) ([]slack.Message, bool, string, error)
+}
+
+var _ WorkspaceAPI = (*slack.Client)(nil)